@@ -34,11 +34,27 @@ Keybindings:
   Tab        Switch between panels
   j/k, ↑/↓   Navigate lists
   s          Start container
-  x          Stop container
-  r          Restart container
+  x          Stop container (untag image in images panel)
+  r          Restart container (run a container from image in images panel)
+  p          Pause/unpause container (pull images in images panel)
+  P          Show the pull queue (in images panel; x cancels, r/R retry)
+  L          Log in to a registry (in images panel)
+  u          Push the selected tag (in images panel)
+  K          Send a signal to container (SIGKILL, SIGHUP, ...)
+  e          Open a shell in container (exit it to return)
+  t          Container processes (tag image in images panel)
+  c          Files changed in container since it was created
+  f          Browse container files (d downloads, u uploads)
   d          Delete container/image
   a          Toggle autostart
-  p          Pull image (in images panel)
+  Space      Mark container (s/x/r/p/K/d/a act on all marked)
+  Ctrl+A     Mark all listed containers
+  *          Invert marks
+  i          Container details (image layers and config in images panel)
+  g          Container history graphs
+  < / >      Change sort column
+  I          Reverse sort order
+  H          Switch Docker host/context
   Enter      View full logs
   /          Filter
   G          Scroll to bottom (in logs)
@@ -85,8 +101,13 @@ func runTUI() {
 		cfg = &config.DefaultConfig
 	}
 
-	// Create Docker client
-	dockerClient, err := docker.NewClient()
+	// Create Docker client for the configured host or active context
+	host, err := docker.StartupHost(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	dockerClient, err := docker.Connect(host)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error connecting to Docker: %v\n", err)
 		fmt.Fprintln(os.Stderr, "Make sure Docker is running and accessible.")
@@ -103,7 +124,8 @@ func runTUI() {
 	}
 
 	// Create and run the app
-	app := ui.NewApp(dockerClient, cfg)
+	app := ui.NewApp(dockerClient, host, cfg)
+	defer app.Close()
 	p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
 	if _, err := p.Run(); err != nil {
@@ -123,8 +145,13 @@ func runDaemon() {
 		cfg = &config.DefaultConfig
 	}
 
-	// Create Docker client
-	dockerClient, err := docker.NewClient()
+	// Create Docker client for the configured host or active context
+	host, err := docker.StartupHost(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	dockerClient, err := docker.Connect(host)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error connecting to Docker: %v\n", err)
 		os.Exit(1)
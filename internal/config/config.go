@@ -2,17 +2,69 @@ package config
 
 import (
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	RefreshRate   int      `yaml:"refresh_rate"`   // in milliseconds
-	DefaultView   string   `yaml:"default_view"`   // containers, images
-	AutostartList []string `yaml:"autostart_list"` // container names/IDs to autostart
-	LogLines      int      `yaml:"log_lines"`      // number of log lines to show
+	RefreshRate   int          `yaml:"refresh_rate"`      // in milliseconds
+	DefaultView   string       `yaml:"default_view"`      // containers, images
+	AutostartList []string     `yaml:"autostart_list"`    // container names/IDs to autostart
+	LogLines      int          `yaml:"log_lines"`         // log lines to load and keep while following
+	HistorySize   int          `yaml:"history_size"`      // stats samples kept per container for graphs (about one per second)
+	Columns       []string     `yaml:"container_columns"` // optional containers table columns: net, io
+	SortBy        string       `yaml:"sort_by"`           // name, state, cpu, mem, net, uptime, image
+	SortDesc      bool         `yaml:"sort_desc"`         // sort containers in descending order
+	StopTimeout   int          `yaml:"stop_timeout"`      // seconds a container gets to stop before it is killed
+	StopTimeouts  []StopRule   `yaml:"stop_timeouts"`     // per-container overrides of stop_timeout, first match wins
+	Exec          ExecConfig   `yaml:"exec"`              // interactive shell opened with e
+	RunPresets    []RunPreset  `yaml:"run_presets"`       // saved run forms, offered for the image they were saved for
+	PullWorkers   int          `yaml:"pull_concurrency"`  // images pulled at once from the pull queue
+	Host          string       `yaml:"host"`              // host or Docker context to connect to at startup
+	Hosts         []HostConfig `yaml:"hosts"`             // named Docker hosts in addition to Docker contexts
+}
+
+// StopRule overrides the stop timeout of containers whose name matches
+// Pattern, a glob such as "billing-*"
+type StopRule struct {
+	Pattern string `yaml:"pattern"`
+	Timeout int    `yaml:"timeout"` // in seconds
+}
+
+// ExecConfig configures the interactive shell
+type ExecConfig struct {
+	Shells  []string `yaml:"shells"`  // tried in order, the first one present in the container is used
+	User    string   `yaml:"user"`    // user to run the shell as (default: the container's user)
+	WorkDir string   `yaml:"workdir"` // working directory (default: the container's)
+}
+
+// RunPreset is a saved invocation of the run form. List fields take the
+// same values as the matching docker run flags.
+type RunPreset struct {
+	Name          string   `yaml:"name"`
+	Image         string   `yaml:"image"`
+	ContainerName string   `yaml:"container_name,omitempty"`
+	Command       string   `yaml:"command,omitempty"` // split like a shell would
+	Env           []string `yaml:"env,omitempty"`     // KEY=value
+	Ports         []string `yaml:"ports,omitempty"`   // [ip:]host:container[/proto]
+	Volumes       []string `yaml:"volumes,omitempty"` // source:target[:ro]
+	Network       string   `yaml:"network,omitempty"`
+	Restart       string   `yaml:"restart,omitempty"` // no, always, unless-stopped, on-failure[:N]
+	AutoRemove    bool     `yaml:"auto_remove,omitempty"`
+}
+
+// HostConfig is a named Docker engine endpoint
+type HostConfig struct {
+	Name          string `yaml:"name"`
+	Host          string `yaml:"host"` // unix:///var/run/docker.sock, tcp://host:2376, ssh://user@host
+	TLSCACert     string `yaml:"tls_ca_cert,omitempty"`
+	TLSCert       string `yaml:"tls_cert,omitempty"`
+	TLSKey        string `yaml:"tls_key,omitempty"`
+	TLSSkipVerify bool   `yaml:"tls_skip_verify,omitempty"`
 }
 
 var DefaultConfig = Config{
@@ -20,6 +72,14 @@ var DefaultConfig = Config{
 	DefaultView:   "containers",
 	AutostartList: []string{},
 	LogLines:      100,
+	HistorySize:   600,
+	Columns:       []string{"net", "io"},
+	SortBy:        "state",
+	StopTimeout:   10,
+	PullWorkers:   3,
+	Exec: ExecConfig{
+		Shells: []string{"/bin/bash", "/bin/sh"},
+	},
 }
 
 // GetConfigDir returns the platform-specific config directory
@@ -97,6 +157,43 @@ func (c *Config) Save() error {
 	return os.WriteFile(configPath, data, 0644)
 }
 
+// StopTimeoutFor returns how long the named container gets to stop, from
+// the first matching stop_timeouts rule or else stop_timeout
+func (c *Config) StopTimeoutFor(name string) time.Duration {
+	for _, rule := range c.StopTimeouts {
+		if ok, _ := path.Match(rule.Pattern, name); ok {
+			return time.Duration(rule.Timeout) * time.Second
+		}
+	}
+	return time.Duration(c.StopTimeout) * time.Second
+}
+
+// SavePreset adds a run preset, replacing one of the same name
+func (c *Config) SavePreset(p RunPreset) {
+	for i := range c.RunPresets {
+		if c.RunPresets[i].Name == p.Name {
+			c.RunPresets[i] = p
+			return
+		}
+	}
+	c.RunPresets = append(c.RunPresets, p)
+}
+
+// PresetsFor returns the run presets saved for an image, matched by any
+// of its references
+func (c *Config) PresetsFor(refs ...string) []RunPreset {
+	var presets []RunPreset
+	for _, p := range c.RunPresets {
+		for _, ref := range refs {
+			if p.Image == ref {
+				presets = append(presets, p)
+				break
+			}
+		}
+	}
+	return presets
+}
+
 func (c *Config) AddAutostart(containerID string) {
 	for _, id := range c.AutostartList {
 		if id == containerID {
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStopTimeoutFor(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.StopTimeouts = []StopRule{
+		{Pattern: "billing-*", Timeout: 60},
+		{Pattern: "billing-api", Timeout: 5},
+		{Pattern: "proxy", Timeout: 2},
+	}
+
+	tests := []struct {
+		name string
+		want time.Duration
+	}{
+		{"billing-api", 60 * time.Second}, // first match wins
+		{"billing-worker", 60 * time.Second},
+		{"proxy", 2 * time.Second},
+		{"proxy-2", 10 * time.Second},
+		{"web", 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := cfg.StopTimeoutFor(tt.name); got != tt.want {
+			t.Errorf("StopTimeoutFor(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSavePresetReplacesByName(t *testing.T) {
+	var cfg Config
+	cfg.SavePreset(RunPreset{Name: "web", Image: "nginx:latest", Ports: []string{"8080:80"}})
+	cfg.SavePreset(RunPreset{Name: "db", Image: "postgres:16"})
+	cfg.SavePreset(RunPreset{Name: "web", Image: "nginx:latest", Ports: []string{"9090:80"}})
+
+	if len(cfg.RunPresets) != 2 {
+		t.Fatalf("presets = %+v", cfg.RunPresets)
+	}
+	got := cfg.PresetsFor("sha256:abc", "nginx:latest")
+	if len(got) != 1 || got[0].Ports[0] != "9090:80" {
+		t.Errorf("PresetsFor = %+v", got)
+	}
+}
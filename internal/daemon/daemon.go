@@ -13,18 +13,20 @@ import (
 )
 
 type Daemon struct {
-	client   *docker.Client
-	config   *config.Config
-	interval time.Duration
-	logger   *log.Logger
+	client     docker.Runtime
+	config     *config.Config
+	loadConfig func() (*config.Config, error)
+	interval   time.Duration
+	logger     *log.Logger
 }
 
-func New(client *docker.Client, cfg *config.Config) *Daemon {
+func New(client docker.Runtime, cfg *config.Config) *Daemon {
 	return &Daemon{
-		client:   client,
-		config:   cfg,
-		interval: 30 * time.Second,
-		logger:   log.New(os.Stdout, "[dktop-daemon] ", log.LstdFlags),
+		client:     client,
+		config:     cfg,
+		loadConfig: config.Load,
+		interval:   30 * time.Second,
+		logger:     log.New(os.Stdout, "[dktop-daemon] ", log.LstdFlags),
 	}
 }
 
@@ -58,7 +60,7 @@ func (d *Daemon) Run(ctx context.Context) error {
 
 func (d *Daemon) checkAndStartContainers(ctx context.Context) {
 	// Reload config to pick up changes
-	newConfig, err := config.Load()
+	newConfig, err := d.loadConfig()
 	if err == nil {
 		d.config = newConfig
 	}
@@ -88,6 +90,17 @@ func (d *Daemon) checkAndStartContainers(ctx context.Context) {
 			continue
 		}
 
+		// A paused container can't be started; resume it instead
+		if container.State == "paused" {
+			d.logger.Printf("Unpausing container: %s", name)
+			if err := d.client.UnpauseContainer(ctx, container.ID); err != nil {
+				d.logger.Printf("Error unpausing container %s: %v", name, err)
+			} else {
+				d.logger.Printf("Successfully unpaused container: %s", name)
+			}
+			continue
+		}
+
 		if container.State != "running" {
 			d.logger.Printf("Starting container: %s (was %s)", name, container.State)
 			if err := d.client.StartContainer(ctx, container.ID); err != nil {
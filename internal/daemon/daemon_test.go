@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/seb07-cloud/dktop/internal/config"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/docker/dockertest"
+)
+
+func newTestDaemon(fake *dockertest.Runtime, autostart ...string) (*Daemon, *bytes.Buffer) {
+	cfg := &config.Config{AutostartList: autostart}
+	var logs bytes.Buffer
+
+	d := New(fake, cfg)
+	d.loadConfig = func() (*config.Config, error) { return cfg, nil }
+	d.logger = log.New(&logs, "", 0)
+	return d, &logs
+}
+
+func TestStartsStoppedAutostartContainers(t *testing.T) {
+	fake := dockertest.New()
+	fake.AddContainer(docker.ContainerInfo{ID: "a1", Name: "api", State: "exited"})
+	fake.AddContainer(docker.ContainerInfo{ID: "w1", Name: "worker", State: "running"})
+	fake.AddContainer(docker.ContainerInfo{ID: "o1", Name: "other", State: "exited"})
+
+	d, _ := newTestDaemon(fake, "api", "w1")
+	if err := d.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := fake.Calls("StartContainer")
+	if len(calls) != 1 || calls[0].Args[0] != "a1" {
+		t.Fatalf("StartContainer calls = %+v, want one for a1", calls)
+	}
+	if c, _ := fake.Container("o1"); c.State != "exited" {
+		t.Error("container outside the autostart list was started")
+	}
+}
+
+func TestUnpausesPausedAutostartContainers(t *testing.T) {
+	fake := dockertest.New()
+	fake.AddContainer(docker.ContainerInfo{ID: "a1", Name: "api", State: "paused"})
+
+	d, _ := newTestDaemon(fake, "api")
+	_ = d.RunOnce(context.Background())
+
+	if got := len(fake.Calls("StartContainer")); got != 0 {
+		t.Errorf("StartContainer called %d times on a paused container", got)
+	}
+	if calls := fake.Calls("UnpauseContainer"); len(calls) != 1 || calls[0].Args[0] != "a1" {
+		t.Errorf("UnpauseContainer calls = %+v, want one for a1", calls)
+	}
+}
+
+func TestMatchesByIDOrName(t *testing.T) {
+	fake := dockertest.New()
+	fake.AddContainer(docker.ContainerInfo{ID: "a1", Name: "api", State: "exited"})
+	fake.AddContainer(docker.ContainerInfo{ID: "b1", Name: "batch", State: "created"})
+
+	d, _ := newTestDaemon(fake, "api", "b1")
+	_ = d.RunOnce(context.Background())
+
+	if got := len(fake.Calls("StartContainer")); got != 2 {
+		t.Fatalf("StartContainer called %d times, want 2", got)
+	}
+}
+
+func TestMissingContainerIsLogged(t *testing.T) {
+	fake := dockertest.New()
+
+	d, logs := newTestDaemon(fake, "ghost")
+	_ = d.RunOnce(context.Background())
+
+	if !strings.Contains(logs.String(), "Autostart container not found: ghost") {
+		t.Fatalf("missing container not logged:\n%s", logs)
+	}
+}
+
+func TestStartFailureDoesNotStopOthers(t *testing.T) {
+	fake := dockertest.New()
+	fake.AddContainer(docker.ContainerInfo{ID: "a1", Name: "api", State: "exited"})
+	fake.AddContainer(docker.ContainerInfo{ID: "b1", Name: "batch", State: "exited"})
+	fake.FailOn("StartContainer", errors.New("port is already allocated"))
+
+	d, logs := newTestDaemon(fake, "api", "batch")
+	_ = d.RunOnce(context.Background())
+
+	if got := len(fake.Calls("StartContainer")); got != 2 {
+		t.Fatalf("StartContainer called %d times, want 2", got)
+	}
+	if !strings.Contains(logs.String(), "Error starting container api: port is already allocated") {
+		t.Fatalf("start error not logged:\n%s", logs)
+	}
+}
+
+func TestListErrorSkipsCheck(t *testing.T) {
+	fake := dockertest.New()
+	fake.FailOn("ListContainers", errors.New("daemon unavailable"))
+
+	d, logs := newTestDaemon(fake, "api")
+	_ = d.RunOnce(context.Background())
+
+	if got := len(fake.Calls("StartContainer")); got != 0 {
+		t.Fatalf("StartContainer called %d times, want 0", got)
+	}
+	if !strings.Contains(logs.String(), "Error listing containers: daemon unavailable") {
+		t.Fatalf("list error not logged:\n%s", logs)
+	}
+}
+
+func TestEmptyAutostartListDoesNothing(t *testing.T) {
+	fake := dockertest.New()
+
+	d, _ := newTestDaemon(fake)
+	_ = d.RunOnce(context.Background())
+
+	if got := len(fake.Calls("ListContainers")); got != 0 {
+		t.Fatalf("ListContainers called %d times, want 0", got)
+	}
+}
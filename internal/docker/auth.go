@@ -0,0 +1,362 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// hubServer is the key the docker CLI stores Docker Hub credentials under
+const hubServer = "https://index.docker.io/v1/"
+
+// tokenUsername is the username credential helpers report for an identity
+// token
+const tokenUsername = "<token>"
+
+// Credentials log in to a registry. If the registry issued an identity
+// token it is used instead of the password.
+type Credentials struct {
+	Server        string
+	Username      string
+	Password      string
+	IdentityToken string
+}
+
+// dockerConfigFile is the part of ~/.docker/config.json about registries
+type dockerConfigFile struct {
+	Auths       map[string]authEntry `json:"auths"`
+	CredsStore  string               `json:"credsStore"`
+	CredHelpers map[string]string    `json:"credHelpers"`
+}
+
+type authEntry struct {
+	Auth          string `json:"auth,omitempty"` // base64 of username:password
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// helperCredentials is what credential helpers read and write
+type helperCredentials struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// RegistryOf returns the registry an image reference is pulled from, as
+// the docker CLI keys its credentials: the registry host, or Docker Hub's
+// index URL for images without one.
+func RegistryOf(ref string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", err
+	}
+	return ServerKey(reference.Domain(named)), nil
+}
+
+// ServerKey normalises a registry address the way credentials are stored:
+// the host name without scheme or path, Docker Hub as its index URL
+func ServerKey(server string) string {
+	host := server
+	host = strings.TrimPrefix(host, "http://")
+	host = strings.TrimPrefix(host, "https://")
+	host, _, _ = strings.Cut(host, "/")
+	switch host {
+	case "", "docker.io", "index.docker.io", "registry-1.docker.io":
+		return hubServer
+	}
+	return host
+}
+
+func readDockerConfig() (dockerConfigFile, error) {
+	var cfg dockerConfigFile
+	dir, err := dockerConfigDir()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse docker config: %w", err)
+	}
+	return cfg, nil
+}
+
+// helperFor returns the credential helper that keeps the credentials of
+// server, if any: one configured for that registry, or the credsStore
+func (cfg dockerConfigFile) helperFor(server string) string {
+	for key, helper := range cfg.CredHelpers {
+		if ServerKey(key) == server {
+			return helper
+		}
+	}
+	return cfg.CredsStore
+}
+
+// LookupCredentials finds the credentials for a registry the way the
+// docker CLI does: from its credential helper, or else from the auths
+// entries of ~/.docker/config.json. ok is false if there are none.
+func LookupCredentials(server string) (creds Credentials, ok bool, err error) {
+	server = ServerKey(server)
+	cfg, err := readDockerConfig()
+	if err != nil {
+		return creds, false, err
+	}
+
+	if helper := cfg.helperFor(server); helper != "" {
+		creds, ok, err = helperGet(helper, server)
+		if ok || err != nil {
+			return creds, ok, err
+		}
+	}
+
+	for key, entry := range cfg.Auths {
+		if ServerKey(key) != server {
+			continue
+		}
+		creds = Credentials{Server: server, IdentityToken: entry.IdentityToken}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return creds, false, fmt.Errorf("credentials for %s: %w", server, err)
+			}
+			creds.Username, creds.Password, _ = strings.Cut(string(decoded), ":")
+		}
+		if creds.Username != "" || creds.IdentityToken != "" {
+			return creds, true, nil
+		}
+	}
+	return creds, false, nil
+}
+
+// StoreCredentials saves credentials like docker login does: with the
+// registry's credential helper if one is configured, otherwise as an auths
+// entry in ~/.docker/config.json. Other settings in the file, other
+// registries' entries and fields of the entry this doesn't know are kept.
+func StoreCredentials(creds Credentials) error {
+	creds.Server = ServerKey(creds.Server)
+	dir, err := dockerConfigDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "config.json")
+
+	raw := map[string]json.RawMessage{}
+	auths := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	var cfg dockerConfigFile
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("parse docker config: %w", err)
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("parse docker config: %w", err)
+		}
+		if len(raw["auths"]) > 0 {
+			if err := json.Unmarshal(raw["auths"], &auths); err != nil {
+				return fmt.Errorf("parse docker config: %w", err)
+			}
+		}
+	}
+
+	// The entry is stored under the normalised key; entries under other
+	// spellings of the same registry are folded into it
+	entry := map[string]json.RawMessage{}
+	for key, value := range auths {
+		if ServerKey(key) != creds.Server {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(value, &fields); err != nil {
+			return fmt.Errorf("parse docker config: %w", err)
+		}
+		for name, v := range fields {
+			if _, ok := entry[name]; !ok || key == creds.Server {
+				entry[name] = v
+			}
+		}
+		delete(auths, key)
+	}
+
+	// The docker CLI keeps an entry without secrets for registries whose
+	// credentials live in a helper
+	delete(entry, "auth")
+	delete(entry, "identitytoken")
+	if helper := cfg.helperFor(creds.Server); helper != "" {
+		if err := helperStore(helper, creds); err != nil {
+			return err
+		}
+	} else {
+		if creds.Username != "" {
+			auth := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+			entry["auth"], _ = json.Marshal(auth)
+		}
+		if creds.IdentityToken != "" {
+			entry["identitytoken"], _ = json.Marshal(creds.IdentityToken)
+		}
+	}
+	if auths[creds.Server], err = json.Marshal(entry); err != nil {
+		return err
+	}
+
+	if raw["auths"], err = json.Marshal(auths); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(raw, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0o600)
+}
+
+// writeFileAtomic replaces a file by renaming a new one over it, so a
+// crash or a concurrent writer never leaves it truncated. A symlinked file
+// is replaced where the link points.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // fails harmlessly once renamed
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// helperGet asks a credential helper, docker-credential-<helper>, for the
+// credentials of server
+func helperGet(helper, server string) (Credentials, bool, error) {
+	var out bytes.Buffer
+	if err := runHelper(helper, "get", strings.NewReader(server), &out); err != nil {
+		if strings.Contains(err.Error(), "credentials not found") {
+			return Credentials{}, false, nil
+		}
+		return Credentials{}, false, err
+	}
+	var hc helperCredentials
+	if err := json.Unmarshal(out.Bytes(), &hc); err != nil {
+		return Credentials{}, false, fmt.Errorf("docker-credential-%s: %w", helper, err)
+	}
+	creds := Credentials{Server: server, Username: hc.Username, Password: hc.Secret}
+	if hc.Username == tokenUsername {
+		creds = Credentials{Server: server, IdentityToken: hc.Secret}
+	}
+	return creds, true, nil
+}
+
+// helperStore hands credentials to a credential helper
+func helperStore(helper string, creds Credentials) error {
+	hc := helperCredentials{ServerURL: creds.Server, Username: creds.Username, Secret: creds.Password}
+	if creds.IdentityToken != "" {
+		hc.Username, hc.Secret = tokenUsername, creds.IdentityToken
+	}
+	data, err := json.Marshal(hc)
+	if err != nil {
+		return err
+	}
+	return runHelper(helper, "store", bytes.NewReader(data), nil)
+}
+
+// runHelper runs a credential helper action. Helpers report failures such
+// as missing credentials on stdout, which becomes the error.
+func runHelper(helper, action string, stdin io.Reader, stdout io.Writer) error {
+	name := "docker-credential-" + helper
+	var out, stderr bytes.Buffer
+	cmd := exec.Command(name, action)
+	cmd.Stdin = stdin
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(out.String() + " " + stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("%s %s: %s", name, action, msg)
+	}
+	if stdout != nil {
+		_, err := stdout.Write(out.Bytes())
+		return err
+	}
+	return nil
+}
+
+// authConfig converts credentials to what the engine API takes
+func (c Credentials) authConfig() registry.AuthConfig {
+	return registry.AuthConfig{
+		ServerAddress: c.Server,
+		Username:      c.Username,
+		Password:      c.Password,
+		IdentityToken: c.IdentityToken,
+	}
+}
+
+// registryAuth returns the RegistryAuth value for pulling or pushing ref,
+// with the stored credentials of its registry if there are any
+func registryAuth(ref string) (string, error) {
+	server, err := RegistryOf(ref)
+	if err != nil {
+		return "", err
+	}
+	creds, ok, err := LookupCredentials(server)
+	if err != nil {
+		return "", fmt.Errorf("credentials for %s: %w", server, err)
+	}
+	if !ok {
+		return registry.EncodeAuthConfig(registry.AuthConfig{})
+	}
+	return registry.EncodeAuthConfig(creds.authConfig())
+}
+
+// Login checks credentials with the registry through the engine, like
+// docker login. The registry may answer with an identity token, which is
+// returned in place of the password.
+func (c *Client) Login(ctx context.Context, creds Credentials) (Credentials, error) {
+	creds.Server = ServerKey(creds.Server)
+	resp, err := c.cli.RegistryLogin(ctx, creds.authConfig())
+	if err != nil {
+		return creds, err
+	}
+	if resp.IdentityToken != "" {
+		creds.Password = ""
+		creds.IdentityToken = resp.IdentityToken
+	}
+	return creds, nil
+}
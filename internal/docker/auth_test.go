@@ -0,0 +1,144 @@
+package docker
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRegistryOf(t *testing.T) {
+	for ref, want := range map[string]string{
+		"nginx":                           hubServer,
+		"library/nginx:1.27":              hubServer,
+		"docker.io/acme/api":              hubServer,
+		"ghcr.io/acme/api:v2":             "ghcr.io",
+		"localhost:5000/app":              "localhost:5000",
+		"registry.example.com:8443/a/b":   "registry.example.com:8443",
+		"https://registry.example.com/":   "",
+		"registry.example.com/app@sha256": "",
+	} {
+		got, err := RegistryOf(ref)
+		if want == "" {
+			if err == nil {
+				t.Errorf("RegistryOf(%q) = %q, want an error", ref, got)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("RegistryOf(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+}
+
+// writeDockerConfig points DOCKER_CONFIG at a new directory holding config
+func writeDockerConfig(t *testing.T, config string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLookupCredentialsFromAuths(t *testing.T) {
+	// "ci:s3cr:t" in base64; passwords may contain colons
+	writeDockerConfig(t, `{"auths": {
+		"https://registry.example.com/v2/": {"auth": "Y2k6czNjcjp0"},
+		"https://index.docker.io/v1/": {"auth": "aHViOnB3", "identitytoken": "tok"}
+	}}`)
+
+	creds, ok, err := LookupCredentials("registry.example.com")
+	if err != nil || !ok || creds.Username != "ci" || creds.Password != "s3cr:t" {
+		t.Errorf("registry.example.com: %+v, %v, %v", creds, ok, err)
+	}
+	creds, ok, err = LookupCredentials("docker.io")
+	if err != nil || !ok || creds.Server != hubServer || creds.IdentityToken != "tok" {
+		t.Errorf("docker.io: %+v, %v, %v", creds, ok, err)
+	}
+	if _, ok, err := LookupCredentials("ghcr.io"); ok || err != nil {
+		t.Errorf("ghcr.io has no credentials, got %v, %v", ok, err)
+	}
+}
+
+func TestStoreCredentialsKeepsOtherSettings(t *testing.T) {
+	dir := writeDockerConfig(t, `{"currentContext": "build-1", "auths": {
+		"https://ghcr.io": {"auth": "b2xkOm9sZA==", "email": "ci@example.com"},
+		"quay.io": {"auth": "cTpx", "registrytoken": "rt"}
+	}}`)
+
+	if err := StoreCredentials(Credentials{Server: "https://ghcr.io", Username: "new", Password: "pw"}); err != nil {
+		t.Fatal(err)
+	}
+	creds, ok, err := LookupCredentials("ghcr.io")
+	if err != nil || !ok || creds.Username != "new" || creds.Password != "pw" {
+		t.Errorf("stored credentials: %+v, %v, %v", creds, ok, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		CurrentContext string                       `json:"currentContext"`
+		Auths          map[string]map[string]string `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.CurrentContext != "build-1" || len(saved.Auths) != 2 ||
+		saved.Auths["ghcr.io"]["email"] != "ci@example.com" || saved.Auths["quay.io"]["registrytoken"] != "rt" {
+		t.Errorf("other settings lost:\n%s", data)
+	}
+	// The file is replaced by a rename, leaving no temporary files behind
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("config dir has %d files, want only config.json", len(entries))
+	}
+}
+
+func TestCredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake helper is a shell script")
+	}
+	bin := t.TempDir()
+	stored := filepath.Join(bin, "stored")
+	helper := `#!/bin/sh
+case "$1" in
+get)
+	read server
+	if [ "$server" = registry.example.com ]; then
+		echo '{"ServerURL":"registry.example.com","Username":"<token>","Secret":"abc"}'
+	else
+		echo "credentials not found in native keychain"
+		exit 1
+	fi ;;
+store)
+	cat > ` + stored + ` ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "docker-credential-test"), []byte(helper), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	dir := writeDockerConfig(t, `{"credHelpers": {"registry.example.com": "test"}}`)
+
+	creds, ok, err := LookupCredentials("registry.example.com")
+	if err != nil || !ok || creds.IdentityToken != "abc" {
+		t.Errorf("helper credentials: %+v, %v, %v", creds, ok, err)
+	}
+
+	if err := StoreCredentials(Credentials{Server: "registry.example.com", Username: "ci", Password: "pw"}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(stored)
+	if !strings.Contains(string(got), `"Username":"ci"`) || !strings.Contains(string(got), `"Secret":"pw"`) {
+		t.Errorf("helper got %s", got)
+	}
+	// The password stays out of config.json
+	data, _ := os.ReadFile(filepath.Join(dir, "config.json"))
+	if strings.Contains(string(data), `"auth"`) {
+		t.Errorf("credentials written to config.json:\n%s", data)
+	}
+}
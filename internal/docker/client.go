@@ -2,13 +2,14 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
 	"io"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
 )
@@ -16,23 +17,37 @@ import (
 type Client struct {
 	cli *client.Client
 	mu  sync.RWMutex
+
+	ttyMu    sync.Mutex
+	ttyCache map[string]bool
 }
 
 type ContainerInfo struct {
-	ID        string
-	Name      string
-	Image     string
-	Status    string
-	State     string
-	Ports     string
-	Created   time.Time
-	CPUPerc   float64
-	MemUsage  uint64
-	MemLimit  uint64
-	MemPerc   float64
-	NetRx     uint64
-	NetTx     uint64
-	Autostart bool
+	ID         string
+	Name       string
+	Image      string
+	Status     string
+	State      string
+	Ports      string
+	Created    time.Time
+	StartedAt  time.Time // approximate, derived from Status; zero unless running
+	CPUPerc    float64
+	MemUsage   uint64
+	MemLimit   uint64
+	MemPerc    float64
+	NetRx      uint64
+	NetTx      uint64
+	BlockRead  uint64
+	BlockWrite uint64
+	PIDs       uint64
+	Sampled    time.Time // when the stats were read by the daemon
+	Autostart  bool
+
+	// Per-second rates derived from consecutive stats samples
+	NetRxRate      float64
+	NetTxRate      float64
+	BlockReadRate  float64
+	BlockWriteRate float64
 }
 
 type ImageInfo struct {
@@ -81,118 +96,211 @@ func (c *Client) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
 
 	var infos []ContainerInfo
 	for _, cont := range containers {
-		name := ""
-		if len(cont.Names) > 0 {
-			name = strings.TrimPrefix(cont.Names[0], "/")
-		}
-
-		ports := formatPorts(cont.Ports)
-
-		infos = append(infos, ContainerInfo{
-			ID:      cont.ID,
-			Name:    name,
-			Image:   cont.Image,
-			Status:  cont.Status,
-			State:   cont.State,
-			Ports:   ports,
-			Created: time.Unix(cont.Created, 0),
-		})
+		infos = append(infos, toContainerInfo(cont))
 	}
 
 	return infos, nil
 }
 
-func (c *Client) GetContainerStats(ctx context.Context, containerID string) (*ContainerInfo, error) {
-	stats, err := c.cli.ContainerStatsOneShot(ctx, containerID)
+// GetContainer fetches a single container by ID, including stopped ones.
+// It returns nil without error if the container no longer exists.
+func (c *Client) GetContainer(ctx context.Context, containerID string) (*ContainerInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("id", containerID)),
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer stats.Body.Close()
 
-	var statsJSON container.StatsResponse
-	if err := decodeStats(stats.Body, &statsJSON); err != nil {
-		return nil, err
+	for _, cont := range containers {
+		if cont.ID == containerID {
+			info := toContainerInfo(cont)
+			return &info, nil
+		}
 	}
 
-	cpuPercent := calculateCPUPercent(&statsJSON)
-	memUsage := statsJSON.MemoryStats.Usage
-	memLimit := statsJSON.MemoryStats.Limit
-	memPercent := 0.0
-	if memLimit > 0 {
-		memPercent = float64(memUsage) / float64(memLimit) * 100
-	}
+	return nil, nil
+}
 
-	var netRx, netTx uint64
-	for _, net := range statsJSON.Networks {
-		netRx += net.RxBytes
-		netTx += net.TxBytes
-	}
+// StreamContainerStats opens a long-lived stats stream for a container and
+// sends one sample per daemon update (about once a second). The error
+// channel receives exactly one value when the stream ends, which happens
+// when the container stops or ctx is cancelled.
+func (c *Client) StreamContainerStats(ctx context.Context, containerID string) (<-chan ContainerInfo, <-chan error) {
+	out := make(chan ContainerInfo)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+
+		stats, err := c.cli.ContainerStats(ctx, containerID, true)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer stats.Body.Close()
+
+		decoder := json.NewDecoder(stats.Body)
+		for {
+			var statsJSON container.StatsResponse
+			if err := decoder.Decode(&statsJSON); err != nil {
+				errs <- err
+				return
+			}
+
+			select {
+			case out <- *statsToInfo(containerID, &statsJSON):
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
 
-	return &ContainerInfo{
-		ID:       containerID,
-		CPUPerc:  cpuPercent,
-		MemUsage: memUsage,
-		MemLimit: memLimit,
-		MemPerc:  memPercent,
-		NetRx:    netRx,
-		NetTx:    netTx,
-	}, nil
+	return out, errs
 }
 
 func (c *Client) StartContainer(ctx context.Context, containerID string) error {
 	return c.cli.ContainerStart(ctx, containerID, container.StartOptions{})
 }
 
-func (c *Client) StopContainer(ctx context.Context, containerID string) error {
-	timeout := 10
-	return c.cli.ContainerStop(ctx, containerID, container.StopOptions{Timeout: &timeout})
+// StopContainer sends the container's stop signal and kills it if it is
+// still running after timeout
+func (c *Client) StopContainer(ctx context.Context, containerID string, timeout time.Duration) error {
+	seconds := int(timeout.Round(time.Second) / time.Second)
+	return c.cli.ContainerStop(ctx, containerID, container.StopOptions{Timeout: &seconds})
+}
+
+func (c *Client) RestartContainer(ctx context.Context, containerID string, timeout time.Duration) error {
+	seconds := int(timeout.Round(time.Second) / time.Second)
+	return c.cli.ContainerRestart(ctx, containerID, container.StopOptions{Timeout: &seconds})
+}
+
+// KillContainer sends signal, e.g. "SIGKILL" or "SIGHUP", to the
+// container's main process
+func (c *Client) KillContainer(ctx context.Context, containerID, signal string) error {
+	return c.cli.ContainerKill(ctx, containerID, signal)
+}
+
+// PauseContainer freezes all processes of a running container
+func (c *Client) PauseContainer(ctx context.Context, containerID string) error {
+	return c.cli.ContainerPause(ctx, containerID)
 }
 
-func (c *Client) RestartContainer(ctx context.Context, containerID string) error {
-	timeout := 10
-	return c.cli.ContainerRestart(ctx, containerID, container.StopOptions{Timeout: &timeout})
+func (c *Client) UnpauseContainer(ctx context.Context, containerID string) error {
+	return c.cli.ContainerUnpause(ctx, containerID)
 }
 
-func (c *Client) RemoveContainer(ctx context.Context, containerID string, force bool) error {
-	return c.cli.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: force})
+// RemoveContainer removes a container. removeVolumes also removes the
+// anonymous volumes attached to it.
+func (c *Client) RemoveContainer(ctx context.Context, containerID string, force, removeVolumes bool) error {
+	return c.cli.ContainerRemove(ctx, containerID, container.RemoveOptions{
+		Force:         force,
+		RemoveVolumes: removeVolumes,
+	})
 }
 
-func (c *Client) GetContainerLogs(ctx context.Context, containerID string, lines int) (string, error) {
+func (c *Client) GetContainerLogs(ctx context.Context, containerID string, lines int) ([]LogLine, error) {
+	tty, err := c.isTTY(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
 	options := container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
-		Tail:       string(rune(lines)),
+		Tail:       formatInt(lines),
 		Timestamps: true,
 	}
 
-	// Fix: convert int to string properly
-	options.Tail = formatInt(lines)
-
 	reader, err := c.cli.ContainerLogs(ctx, containerID, options)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer reader.Close()
 
-	logs, err := io.ReadAll(reader)
+	var logs []LogLine
+	err = demuxLogs(reader, tty, func(line LogLine) {
+		logs = append(logs, line)
+	})
+	return logs, err
+}
+
+// isTTY reports whether a container was created with a TTY, in which case
+// its logs are a raw stream instead of a multiplexed one. The setting can't
+// change after creation, so it is cached per container.
+func (c *Client) isTTY(ctx context.Context, containerID string) (bool, error) {
+	c.ttyMu.Lock()
+	tty, ok := c.ttyCache[containerID]
+	c.ttyMu.Unlock()
+	if ok {
+		return tty, nil
+	}
+
+	info, err := c.cli.ContainerInspect(ctx, containerID)
 	if err != nil {
-		return "", err
+		return false, err
 	}
+	tty = info.Config != nil && info.Config.Tty
 
-	// Strip docker log header bytes (8 bytes per line for multiplexed streams)
-	return stripLogHeaders(string(logs)), nil
+	c.ttyMu.Lock()
+	if c.ttyCache == nil {
+		c.ttyCache = make(map[string]bool)
+	}
+	c.ttyCache[containerID] = tty
+	c.ttyMu.Unlock()
+
+	return tty, nil
 }
 
-func (c *Client) StreamContainerLogs(ctx context.Context, containerID string, lines int) (io.ReadCloser, error) {
-	options := container.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Tail:       formatInt(lines),
-		Follow:     true,
-		Timestamps: true,
-	}
+// StreamContainerLogs follows a container's logs, starting with the last
+// lines lines. The error channel receives exactly one value when the
+// stream ends: nil once the container stops, otherwise the error.
+func (c *Client) StreamContainerLogs(ctx context.Context, containerID string, lines int) (<-chan LogLine, <-chan error) {
+	out := make(chan LogLine)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+
+		tty, err := c.isTTY(ctx, containerID)
+		if err != nil {
+			errs <- err
+			return
+		}
 
-	return c.cli.ContainerLogs(ctx, containerID, options)
+		options := container.LogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Tail:       formatInt(lines),
+			Follow:     true,
+			Timestamps: true,
+		}
+
+		reader, err := c.cli.ContainerLogs(ctx, containerID, options)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer reader.Close()
+
+		err = demuxLogs(reader, tty, func(line LogLine) {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+			}
+		})
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		errs <- err
+	}()
+
+	return out, errs
 }
 
 func (c *Client) ListImages(ctx context.Context) ([]ImageInfo, error) {
@@ -217,12 +325,37 @@ func (c *Client) ListImages(ctx context.Context) ([]ImageInfo, error) {
 	return infos, nil
 }
 
+// PullImage pulls an image with the credentials the docker CLI stored for
+// its registry, if any
 func (c *Client) PullImage(ctx context.Context, refStr string) (io.ReadCloser, error) {
-	return c.cli.ImagePull(ctx, refStr, image.PullOptions{})
+	auth, err := registryAuth(refStr)
+	if err != nil {
+		return nil, err
+	}
+	return c.cli.ImagePull(ctx, refStr, image.PullOptions{RegistryAuth: auth})
 }
 
-func (c *Client) RemoveImage(ctx context.Context, imageID string, force bool) error {
-	_, err := c.cli.ImageRemove(ctx, imageID, image.RemoveOptions{Force: force})
+// PushImage pushes a tag to its registry with the credentials the docker
+// CLI stored for it. Like a pull it streams progress messages.
+func (c *Client) PushImage(ctx context.Context, ref string) (io.ReadCloser, error) {
+	auth, err := registryAuth(ref)
+	if err != nil {
+		return nil, err
+	}
+	return c.cli.ImagePush(ctx, ref, image.PushOptions{RegistryAuth: auth})
+}
+
+// TagImage creates the reference target for the image source names, which
+// may be an ID or another reference. An existing target is moved over.
+func (c *Client) TagImage(ctx context.Context, source, target string) error {
+	return c.cli.ImageTag(ctx, source, target)
+}
+
+// RemoveImage removes an image reference. Given a tag it only untags,
+// unless it is the last tag of the image; given an ID it deletes the image,
+// which needs force if the image is tagged in several repositories.
+func (c *Client) RemoveImage(ctx context.Context, ref string, force bool) error {
+	_, err := c.cli.ImageRemove(ctx, ref, image.RemoveOptions{Force: force, PruneChildren: true})
 	return err
 }
 
@@ -254,3 +387,29 @@ func (c *Client) SetRestartPolicy(ctx context.Context, containerID string, polic
 func (c *Client) GetContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
 	return c.cli.ContainerInspect(ctx, containerID)
 }
+
+// FilesystemDiff lists what changed in a container's writable layer
+type FilesystemDiff struct {
+	Changes []container.FilesystemChange
+	SizeRw  int64 // size of the files added or changed, as estimated by the daemon
+}
+
+// GetContainerDiff compares a container's filesystem with its image. The
+// daemon has to walk the writable layer for the size, which can take a
+// while for large layers.
+func (c *Client) GetContainerDiff(ctx context.Context, containerID string) (FilesystemDiff, error) {
+	changes, err := c.cli.ContainerDiff(ctx, containerID)
+	if err != nil {
+		return FilesystemDiff{}, err
+	}
+
+	diff := FilesystemDiff{Changes: changes}
+	info, _, err := c.cli.ContainerInspectWithRaw(ctx, containerID, true)
+	if err != nil {
+		return FilesystemDiff{}, err
+	}
+	if info.SizeRw != nil {
+		diff.SizeRw = *info.SizeRw
+	}
+	return diff, nil
+}
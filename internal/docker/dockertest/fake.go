@@ -0,0 +1,1014 @@
+// Package dockertest provides an in-memory docker.Runtime for tests.
+package dockertest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/seb07-cloud/dktop/internal/docker"
+)
+
+// Call records a single method invocation on the fake
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// Runtime is a scriptable in-memory container runtime. Seed it with
+// containers, images and logs, make methods fail with FailOn, push events
+// and stats samples, then inspect the recorded calls and resulting state.
+type Runtime struct {
+	mu         sync.Mutex
+	containers []docker.ContainerInfo
+	images     []docker.ImageInfo
+	logs       map[string][]docker.LogLine
+	policies   map[string]string
+	inspects   map[string]types.ContainerJSON
+	execOutput map[string]string
+	processes  map[string][]docker.ProcessInfo
+	diffs      map[string]docker.FilesystemDiff
+	files      map[string]map[string]string
+	pulls      map[string]pullScript
+	pushes     map[string]pullScript
+	imageInfo  map[string]docker.ImageDetail
+	failures   map[string]error
+	calls      []Call
+
+	events    chan docker.Event
+	eventErrs chan error
+	stats     map[string]chan docker.ContainerInfo
+	logFeeds  map[string]*logFeed
+}
+
+type logFeed struct {
+	lines chan docker.LogLine
+	end   chan struct{}
+}
+
+var _ docker.Runtime = (*Runtime)(nil)
+
+func New() *Runtime {
+	return &Runtime{
+		logs:       make(map[string][]docker.LogLine),
+		policies:   make(map[string]string),
+		inspects:   make(map[string]types.ContainerJSON),
+		execOutput: make(map[string]string),
+		processes:  make(map[string][]docker.ProcessInfo),
+		diffs:      make(map[string]docker.FilesystemDiff),
+		files:      make(map[string]map[string]string),
+		pulls:      make(map[string]pullScript),
+		pushes:     make(map[string]pullScript),
+		imageInfo:  make(map[string]docker.ImageDetail),
+		failures:   make(map[string]error),
+		events:     make(chan docker.Event, 64),
+		eventErrs:  make(chan error, 1),
+		stats:      make(map[string]chan docker.ContainerInfo),
+		logFeeds:   make(map[string]*logFeed),
+	}
+}
+
+// AddContainer adds a container to the fake engine
+func (r *Runtime) AddContainer(c docker.ContainerInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.containers = append(r.containers, c)
+}
+
+// AddImage adds an image to the fake engine
+func (r *Runtime) AddImage(img docker.ImageInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.images = append(r.images, img)
+}
+
+// SetLogs sets the full log output of a container
+func (r *Runtime) SetLogs(containerID string, lines ...docker.LogLine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs[containerID] = lines
+}
+
+// AppendLogs adds lines to a container's logs and delivers them to an open
+// follow stream
+func (r *Runtime) AppendLogs(containerID string, lines ...docker.LogLine) {
+	r.mu.Lock()
+	r.logs[containerID] = append(r.logs[containerID], lines...)
+	feed := r.logFeeds[containerID]
+	r.mu.Unlock()
+
+	if feed != nil {
+		for _, line := range lines {
+			feed.lines <- line
+		}
+	}
+}
+
+// EndLogs ends the follow stream of a container the way the daemon does
+// when the container stops
+func (r *Runtime) EndLogs(containerID string) {
+	r.mu.Lock()
+	feed := r.logFeeds[containerID]
+	delete(r.logFeeds, containerID)
+	r.mu.Unlock()
+
+	if feed != nil {
+		close(feed.end)
+	}
+}
+
+// SetInspect sets the inspect result returned for a container instead of
+// the minimal one derived from its list entry
+func (r *Runtime) SetInspect(containerID string, info types.ContainerJSON) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inspects[containerID] = info
+}
+
+// SetExecOutput sets what shell sessions in a container print before they
+// exit
+func (r *Runtime) SetExecOutput(containerID, output string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.execOutput[containerID] = output
+}
+
+// SetDiff sets the filesystem changes reported for a container
+func (r *Runtime) SetDiff(containerID string, diff docker.FilesystemDiff) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.diffs[containerID] = diff
+}
+
+// SetImageDetail sets what InspectImage returns for an image, keyed by
+// its ID
+func (r *Runtime) SetImageDetail(imageID string, detail docker.ImageDetail) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.imageInfo[imageID] = detail
+}
+
+// AddFile puts a file into a container's filesystem; its directories
+// exist implicitly
+func (r *Runtime) AddFile(containerID, p, content string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.files[containerID] == nil {
+		r.files[containerID] = make(map[string]string)
+	}
+	r.files[containerID][p] = content
+}
+
+// File returns the content of a file in a container
+func (r *Runtime) File(containerID, p string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	content, ok := r.files[containerID][p]
+	return content, ok
+}
+
+// pullScript is the progress stream of a pull or push. A held one keeps
+// its stream open after the output until its context is cancelled.
+type pullScript struct {
+	output string
+	hold   bool
+}
+
+// SetPullOutput sets the progress messages streamed by a pull of ref, one
+// JSON object per line. If hold is set the pull never finishes on its own.
+func (r *Runtime) SetPullOutput(ref, output string, hold bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pulls[ref] = pullScript{output: output, hold: hold}
+}
+
+// SetPushOutput sets the progress messages streamed by a push of ref, like
+// SetPullOutput does for pulls
+func (r *Runtime) SetPushOutput(ref, output string, hold bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pushes[ref] = pullScript{output: output, hold: hold}
+}
+
+// SetProcesses sets the processes listed for a container
+func (r *Runtime) SetProcesses(containerID string, procs []docker.ProcessInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.processes[containerID] = procs
+}
+
+// FailOn makes every later call to method return err. A nil err clears it.
+func (r *Runtime) FailOn(method string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		delete(r.failures, method)
+		return
+	}
+	r.failures[method] = err
+}
+
+// Calls returns the recorded calls to method, in order
+func (r *Runtime) Calls(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var calls []Call
+	for _, c := range r.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Container returns the current state of a container
+func (r *Runtime) Container(containerID string) (docker.ContainerInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := r.indexOf(containerID); i >= 0 {
+		return r.containers[i], true
+	}
+	return docker.ContainerInfo{}, false
+}
+
+// RestartPolicy returns the restart policy last set on a container
+func (r *Runtime) RestartPolicy(containerID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.policies[containerID]
+}
+
+// Emit publishes an event to the current Events subscriber
+func (r *Runtime) Emit(ev docker.Event) {
+	r.events <- ev
+}
+
+// FailEvents ends the current Events subscription with err
+func (r *Runtime) FailEvents(err error) {
+	r.eventErrs <- err
+}
+
+// SendStats delivers a sample on the open stats stream of sample.ID. It
+// reports false if nothing is streaming stats for that container.
+func (r *Runtime) SendStats(sample docker.ContainerInfo) bool {
+	r.mu.Lock()
+	feed, ok := r.stats[sample.ID]
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+	feed <- sample
+	return true
+}
+
+// record logs a call and returns the failure scripted for the method, if any
+func (r *Runtime) record(method string, args ...any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+	return r.failures[method]
+}
+
+func (r *Runtime) indexOf(containerID string) int {
+	for i, c := range r.containers {
+		if c.ID == containerID || c.Name == containerID {
+			return i
+		}
+	}
+	return -1
+}
+
+func noSuchContainer(containerID string) error {
+	return errors.New("No such container: " + containerID)
+}
+
+func (r *Runtime) Ping(ctx context.Context) error {
+	return r.record("Ping")
+}
+
+func (r *Runtime) Close() error {
+	return r.record("Close")
+}
+
+func (r *Runtime) ListContainers(ctx context.Context) ([]docker.ContainerInfo, error) {
+	if err := r.record("ListContainers"); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]docker.ContainerInfo(nil), r.containers...), nil
+}
+
+func (r *Runtime) GetContainer(ctx context.Context, containerID string) (*docker.ContainerInfo, error) {
+	if err := r.record("GetContainer", containerID); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(containerID)
+	if i < 0 {
+		return nil, nil
+	}
+	c := r.containers[i]
+	return &c, nil
+}
+
+func (r *Runtime) GetContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	if err := r.record("GetContainerInspect", containerID); err != nil {
+		return types.ContainerJSON{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(containerID)
+	if i < 0 {
+		return types.ContainerJSON{}, noSuchContainer(containerID)
+	}
+	c := r.containers[i]
+	if info, ok := r.inspects[c.ID]; ok {
+		return info, nil
+	}
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:      c.ID,
+			Name:    "/" + c.Name,
+			Created: c.Created.Format("2006-01-02T15:04:05Z"),
+			State: &types.ContainerState{
+				Status:  c.State,
+				Running: c.State == "running",
+				Paused:  c.State == "paused",
+			},
+			HostConfig: &container.HostConfig{
+				RestartPolicy: container.RestartPolicy{
+					Name: container.RestartPolicyMode(r.policies[c.ID]),
+				},
+			},
+		},
+		Config: &container.Config{Image: c.Image},
+	}, nil
+}
+
+// setState moves a container to state. If from is set, the container must
+// be in that state first.
+func (r *Runtime) setState(method, containerID, from, state, status string, args ...any) error {
+	if err := r.record(method, append([]any{containerID}, args...)...); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(containerID)
+	if i < 0 {
+		return noSuchContainer(containerID)
+	}
+	if from != "" && r.containers[i].State != from {
+		return errors.New("Container " + containerID + " is not " + from)
+	}
+	r.containers[i].State = state
+	r.containers[i].Status = status
+	return nil
+}
+
+// CreateContainer adds a created container named after opts.Name, or
+// "created-N" without one
+func (r *Runtime) CreateContainer(ctx context.Context, opts docker.RunOptions) (string, error) {
+	if err := r.record("CreateContainer", opts); err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := opts.Name
+	if name == "" {
+		name = "created-" + strconv.Itoa(len(r.containers)+1)
+	}
+	if r.indexOf(name) >= 0 {
+		return "", errors.New("Conflict. The container name \"/" + name + "\" is already in use")
+	}
+	id := name + "-id"
+	r.containers = append(r.containers, docker.ContainerInfo{
+		ID:     id,
+		Name:   name,
+		Image:  opts.Image,
+		State:  "created",
+		Status: "Created",
+	})
+	return id, nil
+}
+
+func (r *Runtime) StartContainer(ctx context.Context, containerID string) error {
+	return r.setState("StartContainer", containerID, "", "running", "Up Less than a second")
+}
+
+func (r *Runtime) StopContainer(ctx context.Context, containerID string, timeout time.Duration) error {
+	return r.setState("StopContainer", containerID, "", "exited", "Exited (0) Less than a second ago", timeout)
+}
+
+func (r *Runtime) RestartContainer(ctx context.Context, containerID string, timeout time.Duration) error {
+	return r.setState("RestartContainer", containerID, "", "running", "Up Less than a second", timeout)
+}
+
+// KillContainer stops the container for SIGKILL; other signals only need it
+// to be running
+func (r *Runtime) KillContainer(ctx context.Context, containerID, signal string) error {
+	if signal == "SIGKILL" || signal == "KILL" {
+		return r.setState("KillContainer", containerID, "running", "exited", "Exited (137) Less than a second ago", signal)
+	}
+	if err := r.record("KillContainer", containerID, signal); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(containerID)
+	if i < 0 {
+		return noSuchContainer(containerID)
+	}
+	if r.containers[i].State != "running" {
+		return errors.New("Container " + containerID + " is not running")
+	}
+	return nil
+}
+
+// ExecShell starts a session that prints the container's exec output and
+// exits, recording everything written to it
+func (r *Runtime) ExecShell(ctx context.Context, containerID string, opts docker.ExecOptions) (docker.ExecSession, error) {
+	if err := r.record("ExecShell", containerID, opts); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(containerID)
+	if i < 0 {
+		return nil, noSuchContainer(containerID)
+	}
+	if r.containers[i].State != "running" {
+		return nil, errors.New("Container " + containerID + " is not running")
+	}
+	return &ExecSession{output: strings.NewReader(r.execOutput[r.containers[i].ID])}, nil
+}
+
+func (r *Runtime) GetContainerDiff(ctx context.Context, containerID string) (docker.FilesystemDiff, error) {
+	if err := r.record("GetContainerDiff", containerID); err != nil {
+		return docker.FilesystemDiff{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(containerID)
+	if i < 0 {
+		return docker.FilesystemDiff{}, noSuchContainer(containerID)
+	}
+	return r.diffs[r.containers[i].ID], nil
+}
+
+func (r *Runtime) ListProcesses(ctx context.Context, containerID string) ([]docker.ProcessInfo, error) {
+	if err := r.record("ListProcesses", containerID); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(containerID)
+	if i < 0 {
+		return nil, noSuchContainer(containerID)
+	}
+	if r.containers[i].State != "running" {
+		return nil, errors.New("Container " + containerID + " is not running")
+	}
+	return append([]docker.ProcessInfo(nil), r.processes[r.containers[i].ID]...), nil
+}
+
+func (r *Runtime) SignalProcess(ctx context.Context, containerID string, pid int, signal string) error {
+	if err := r.record("SignalProcess", containerID, pid, signal); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.indexOf(containerID) < 0 {
+		return noSuchContainer(containerID)
+	}
+	return nil
+}
+
+// ExecSession is the fake's shell session
+type ExecSession struct {
+	output *strings.Reader
+
+	mu     sync.Mutex
+	input  strings.Builder
+	closed bool
+}
+
+func (s *ExecSession) Read(p []byte) (int, error) {
+	return s.output.Read(p)
+}
+
+func (s *ExecSession) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	return s.input.Write(p)
+}
+
+func (s *ExecSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *ExecSession) Resize(ctx context.Context, width, height uint) error {
+	return nil
+}
+
+func (r *Runtime) PauseContainer(ctx context.Context, containerID string) error {
+	return r.setState("PauseContainer", containerID, "running", "paused", "Up Less than a second (Paused)")
+}
+
+func (r *Runtime) UnpauseContainer(ctx context.Context, containerID string) error {
+	return r.setState("UnpauseContainer", containerID, "paused", "running", "Up Less than a second")
+}
+
+func (r *Runtime) RemoveContainer(ctx context.Context, containerID string, force, removeVolumes bool) error {
+	if err := r.record("RemoveContainer", containerID, force, removeVolumes); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(containerID)
+	if i < 0 {
+		return noSuchContainer(containerID)
+	}
+	if r.containers[i].State == "running" && !force {
+		return errors.New("cannot remove a running container " + containerID)
+	}
+	r.containers = append(r.containers[:i], r.containers[i+1:]...)
+	return nil
+}
+
+func (r *Runtime) SetRestartPolicy(ctx context.Context, containerID string, policy string) error {
+	if err := r.record("SetRestartPolicy", containerID, policy); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.indexOf(containerID) < 0 {
+		return noSuchContainer(containerID)
+	}
+	r.policies[containerID] = policy
+	return nil
+}
+
+// StreamContainerStats opens a stream fed by SendStats until ctx is cancelled
+func (r *Runtime) StreamContainerStats(ctx context.Context, containerID string) (<-chan docker.ContainerInfo, <-chan error) {
+	out := make(chan docker.ContainerInfo)
+	errs := make(chan error, 1)
+
+	if err := r.record("StreamContainerStats", containerID); err != nil {
+		errs <- err
+		close(out)
+		return out, errs
+	}
+
+	feed := make(chan docker.ContainerInfo, 16)
+	r.mu.Lock()
+	r.stats[containerID] = feed
+	r.mu.Unlock()
+
+	go func() {
+		defer close(out)
+		defer func() {
+			r.mu.Lock()
+			if r.stats[containerID] == feed {
+				delete(r.stats, containerID)
+			}
+			r.mu.Unlock()
+		}()
+		for {
+			select {
+			case sample := <-feed:
+				select {
+				case out <- sample:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return out, errs
+}
+
+func (r *Runtime) GetSystemStats(ctx context.Context) (*docker.SystemStats, error) {
+	if err := r.record("GetSystemStats"); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := &docker.SystemStats{
+		Containers: len(r.containers),
+		Images:     len(r.images),
+	}
+	for _, c := range r.containers {
+		switch c.State {
+		case "running":
+			stats.ContainersRunning++
+		case "paused":
+			stats.ContainersPaused++
+		default:
+			stats.ContainersStopped++
+		}
+	}
+	return stats, nil
+}
+
+func (r *Runtime) GetContainerLogs(ctx context.Context, containerID string, lines int) ([]docker.LogLine, error) {
+	if err := r.record("GetContainerLogs", containerID, lines); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.indexOf(containerID) < 0 {
+		return nil, noSuchContainer(containerID)
+	}
+	return tail(r.logs[containerID], lines), nil
+}
+
+// StreamContainerLogs replays the tail of the logs, then follows lines
+// added with AppendLogs until ctx is cancelled or EndLogs is called
+func (r *Runtime) StreamContainerLogs(ctx context.Context, containerID string, lines int) (<-chan docker.LogLine, <-chan error) {
+	out := make(chan docker.LogLine)
+	errs := make(chan error, 1)
+
+	if err := r.record("StreamContainerLogs", containerID, lines); err != nil {
+		errs <- err
+		close(out)
+		return out, errs
+	}
+
+	r.mu.Lock()
+	if r.indexOf(containerID) < 0 {
+		r.mu.Unlock()
+		errs <- noSuchContainer(containerID)
+		close(out)
+		return out, errs
+	}
+	backlog := tail(r.logs[containerID], lines)
+	feed := &logFeed{lines: make(chan docker.LogLine, 64), end: make(chan struct{})}
+	r.logFeeds[containerID] = feed
+	r.mu.Unlock()
+
+	go func() {
+		defer close(out)
+		defer func() {
+			r.mu.Lock()
+			if r.logFeeds[containerID] == feed {
+				delete(r.logFeeds, containerID)
+			}
+			r.mu.Unlock()
+		}()
+		for _, line := range backlog {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+		for {
+			select {
+			case line := <-feed.lines:
+				select {
+				case out <- line:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			case <-feed.end:
+				errs <- nil
+				return
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return out, errs
+}
+
+func (r *Runtime) ListImages(ctx context.Context) ([]docker.ImageInfo, error) {
+	if err := r.record("ListImages"); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]docker.ImageInfo(nil), r.images...), nil
+}
+
+func (r *Runtime) PullImage(ctx context.Context, refStr string) (io.ReadCloser, error) {
+	if err := r.record("PullImage", refStr); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	script := r.pulls[refStr]
+	if !script.hold && !strings.Contains(script.output, `"errorDetail"`) {
+		r.images = append(r.images, docker.ImageInfo{ID: "sha256:" + refStr, Tags: []string{refStr}})
+	}
+	return script.stream(ctx), nil
+}
+
+func (s pullScript) stream(ctx context.Context) io.ReadCloser {
+	if !s.hold {
+		return io.NopCloser(strings.NewReader(s.output))
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(pw, s.output)
+		<-ctx.Done()
+		pw.CloseWithError(ctx.Err())
+	}()
+	return pr
+}
+
+// InspectImage finds an image by ID or tag. Without a detail set for it
+// only the ID, tags and size are filled in.
+func (r *Runtime) InspectImage(ctx context.Context, ref string) (*docker.ImageDetail, error) {
+	if err := r.record("InspectImage", ref); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, img := range r.images {
+		if img.ID != ref && !slices.Contains(img.Tags, ref) {
+			continue
+		}
+		detail, ok := r.imageInfo[img.ID]
+		if !ok {
+			detail = docker.ImageDetail{Size: img.Size, Created: img.Created}
+		}
+		detail.ID, detail.Tags = img.ID, img.Tags
+		return &detail, nil
+	}
+	return nil, errors.New("No such image: " + ref)
+}
+
+// PushImage pushes a tag that exists, streaming the output set with
+// SetPushOutput
+func (r *Runtime) PushImage(ctx context.Context, ref string) (io.ReadCloser, error) {
+	if err := r.record("PushImage", ref); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, img := range r.images {
+		if slices.Contains(img.Tags, ref) {
+			return r.pushes[ref].stream(ctx), nil
+		}
+	}
+	return nil, errors.New("An image does not exist locally with the tag: " + ref)
+}
+
+// TagImage points target at the image source names, moving it off any
+// image it tagged before
+func (r *Runtime) TagImage(ctx context.Context, source, target string) error {
+	if err := r.record("TagImage", source, target); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	src := -1
+	for i, img := range r.images {
+		if img.ID == source || slices.Contains(img.Tags, source) {
+			src = i
+			break
+		}
+	}
+	if src < 0 {
+		return errors.New("No such image: " + source)
+	}
+	if slices.Contains(r.images[src].Tags, target) {
+		return nil
+	}
+	for i, img := range r.images {
+		if j := slices.Index(img.Tags, target); j >= 0 {
+			r.images[i].Tags = append(img.Tags[:j:j], img.Tags[j+1:]...)
+		}
+	}
+	r.images[src].Tags = append(slices.Clip(r.images[src].Tags), target)
+	return nil
+}
+
+// Login accepts any credentials unless FailOn("Login") is set
+func (r *Runtime) Login(ctx context.Context, creds docker.Credentials) (docker.Credentials, error) {
+	if err := r.record("Login", creds); err != nil {
+		return creds, err
+	}
+	creds.Server = docker.ServerKey(creds.Server)
+	return creds, nil
+}
+
+// RemoveImage untags when given a tag and deletes when given an ID, like
+// the engine does
+func (r *Runtime) RemoveImage(ctx context.Context, ref string, force bool) error {
+	if err := r.record("RemoveImage", ref, force); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, img := range r.images {
+		if img.ID == ref {
+			if len(img.Tags) > 1 && !force {
+				return errors.New("conflict: unable to delete " + ref + " (must be forced) - image is referenced in multiple repositories")
+			}
+			r.images = append(r.images[:i], r.images[i+1:]...)
+			return nil
+		}
+		for j, tag := range img.Tags {
+			if tag != ref {
+				continue
+			}
+			if len(img.Tags) == 1 {
+				r.images = append(r.images[:i], r.images[i+1:]...)
+				return nil
+			}
+			r.images[i].Tags = append(img.Tags[:j:j], img.Tags[j+1:]...)
+			return nil
+		}
+	}
+	return errors.New("No such image: " + ref)
+}
+
+// Events forwards everything passed to Emit until ctx is cancelled or
+// FailEvents is called
+func (r *Runtime) Events(ctx context.Context) (<-chan docker.Event, <-chan error) {
+	out := make(chan docker.Event)
+	errs := make(chan error, 1)
+
+	if err := r.record("Events"); err != nil {
+		errs <- err
+		close(out)
+		return out, errs
+	}
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case ev := <-r.events:
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			case err := <-r.eventErrs:
+				errs <- err
+				return
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return out, errs
+}
+
+func tail(logs []docker.LogLine, lines int) []docker.LogLine {
+	if lines > 0 && len(logs) > lines {
+		logs = logs[len(logs)-lines:]
+	}
+	return append([]docker.LogLine(nil), logs...)
+}
+
+// filesUnder returns the files at or below p, by path
+func (r *Runtime) filesUnder(containerID, p string) map[string]string {
+	out := map[string]string{}
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	for name, content := range r.files[containerID] {
+		if name == p || strings.HasPrefix(name, prefix) {
+			out[name] = content
+		}
+	}
+	return out
+}
+
+func noSuchPath(containerID, p string) error {
+	return errors.New("Could not find the file " + p + " in container " + containerID)
+}
+
+func (r *Runtime) StatPath(ctx context.Context, containerID, p string) (docker.FileEntry, error) {
+	if err := r.record("StatPath", containerID, p); err != nil {
+		return docker.FileEntry{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if content, ok := r.files[containerID][p]; ok {
+		return docker.FileEntry{Name: path.Base(p), Size: int64(len(content)), Mode: 0o644}, nil
+	}
+	if len(r.filesUnder(containerID, p)) > 0 || p == "/" {
+		return docker.FileEntry{Name: path.Base(p), Mode: os.ModeDir | 0o755}, nil
+	}
+	return docker.FileEntry{}, noSuchPath(containerID, p)
+}
+
+func (r *Runtime) ListDir(ctx context.Context, containerID, dir string) ([]docker.FileEntry, error) {
+	if err := r.record("ListDir", containerID, dir); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	under := r.filesUnder(containerID, dir)
+	if len(under) == 0 && dir != "/" {
+		return nil, noSuchPath(containerID, dir)
+	}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	seen := map[string]bool{}
+	var entries []docker.FileEntry
+	for name, content := range under {
+		child, rest, nested := strings.Cut(strings.TrimPrefix(name, prefix), "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		if nested || rest != "" {
+			entries = append(entries, docker.FileEntry{Name: child, Size: -1, Mode: os.ModeDir | 0o755})
+		} else {
+			entries = append(entries, docker.FileEntry{Name: child, Size: int64(len(content)), Mode: 0o644})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// DownloadPath writes the files at or below src to the local filesystem,
+// following the same destination rules as the real client
+func (r *Runtime) DownloadPath(ctx context.Context, containerID, src, dst string, progress func(int64)) error {
+	if err := r.record("DownloadPath", containerID, src, dst); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	under := r.filesUnder(containerID, src)
+	r.mu.Unlock()
+	if len(under) == 0 {
+		return noSuchPath(containerID, src)
+	}
+
+	base := dst
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		base = filepath.Join(dst, path.Base(src))
+	}
+	var written int64
+	for name, content := range under {
+		target := filepath.Join(base, filepath.FromSlash(strings.TrimPrefix(name, src)))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			return err
+		}
+		written += int64(len(content))
+		if progress != nil {
+			progress(written)
+		}
+	}
+	return nil
+}
+
+func (r *Runtime) UploadFile(ctx context.Context, containerID, src, dstDir string, progress func(int64)) error {
+	if err := r.record("UploadFile", containerID, src, dstDir); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		progress(int64(len(data)))
+	}
+	r.AddFile(containerID, path.Join(dstDir, filepath.Base(src)), string(data))
+	return nil
+}
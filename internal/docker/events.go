@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// EventKind identifies the type of object a Docker event refers to
+type EventKind string
+
+const (
+	EventContainer EventKind = "container"
+	EventImage     EventKind = "image"
+	EventNetwork   EventKind = "network"
+	EventVolume    EventKind = "volume"
+)
+
+// Event is a simplified Docker engine event
+type Event struct {
+	Kind       EventKind
+	Action     string
+	ID         string
+	Name       string
+	Attributes map[string]string
+	Time       time.Time
+}
+
+// Events subscribes to the Docker /events stream for containers, images,
+// networks and volumes. Events that don't change anything the UI shows
+// (exec sessions, attach, resize, ...) are dropped. The error channel
+// receives exactly one value when the stream ends; cancel ctx to stop it.
+func (c *Client) Events(ctx context.Context) (<-chan Event, <-chan error) {
+	args := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("type", string(events.ImageEventType)),
+		filters.Arg("type", string(events.NetworkEventType)),
+		filters.Arg("type", string(events.VolumeEventType)),
+	)
+
+	msgs, errs := c.cli.Events(ctx, events.ListOptions{Filters: args})
+
+	out := make(chan Event)
+	outErr := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				outErr <- ctx.Err()
+				return
+			case err := <-errs:
+				outErr <- err
+				return
+			case msg := <-msgs:
+				if ignoredEventAction(msg.Action) {
+					continue
+				}
+				ev := Event{
+					Kind:       EventKind(msg.Type),
+					Action:     string(msg.Action),
+					ID:         msg.Actor.ID,
+					Name:       msg.Actor.Attributes["name"],
+					Attributes: msg.Actor.Attributes,
+					Time:       time.Unix(0, msg.TimeNano),
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					outErr <- ctx.Err()
+					return
+				}
+			}
+		}
+	}()
+
+	return out, outErr
+}
+
+// ignoredEventAction reports whether an event action has no visible effect
+// on the container, image or system views
+func ignoredEventAction(action events.Action) bool {
+	if strings.HasPrefix(string(action), "exec_") {
+		return true
+	}
+	switch action {
+	case events.ActionAttach, events.ActionDetach, events.ActionResize,
+		events.ActionTop, events.ActionCopy, events.ActionArchivePath,
+		events.ActionExtractToDir, events.ActionExport, events.ActionCommit,
+		events.ActionMount, events.ActionUnmount:
+		return true
+	}
+	return false
+}
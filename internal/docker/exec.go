@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ExecOptions configures an interactive shell in a container
+type ExecOptions struct {
+	Shells  []string // candidates, first one present in the container wins
+	User    string
+	WorkDir string
+	Width   uint // initial TTY size
+	Height  uint
+}
+
+// ExecSession is an interactive exec attached to a TTY. Reads return the
+// shell's output until it exits; writes are its input.
+type ExecSession interface {
+	io.ReadWriteCloser
+	Resize(ctx context.Context, width, height uint) error
+}
+
+// ExecShell starts the first of opts.Shells found in the container with a
+// TTY attached
+func (c *Client) ExecShell(ctx context.Context, containerID string, opts ExecOptions) (ExecSession, error) {
+	shell, err := pickShell(opts.Shells, func(path string) bool {
+		_, err := c.cli.ContainerStatPath(ctx, containerID, path)
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var size *[2]uint
+	if opts.Width > 0 && opts.Height > 0 {
+		size = &[2]uint{opts.Height, opts.Width}
+	}
+
+	created, err := c.cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		User:         opts.User,
+		WorkingDir:   opts.WorkDir,
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		ConsoleSize:  size,
+		Cmd:          []string{shell},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{
+		Tty:         true,
+		ConsoleSize: size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &execSession{cli: c, id: created.ID, resp: resp}, nil
+}
+
+// pickShell returns the first shell that exists. Shells given without a
+// path can't be checked and are tried as is.
+func pickShell(shells []string, exists func(path string) bool) (string, error) {
+	for _, shell := range shells {
+		if !strings.HasPrefix(shell, "/") || exists(shell) {
+			return shell, nil
+		}
+	}
+	return "", fmt.Errorf("no shell found in container (tried %s)", strings.Join(shells, ", "))
+}
+
+// execQuiet runs cmd in a container and waits for it, returning its
+// stdout, or its stderr as the error if it fails
+func (c *Client) execQuiet(ctx context.Context, containerID string, cmd []string) (string, error) {
+	created, err := c.cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Close()
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, resp.Reader); err != nil {
+		return "", err
+	}
+
+	inspect, err := c.cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return "", err
+	}
+	if inspect.ExitCode != 0 {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = fmt.Sprintf("exit code %d", inspect.ExitCode)
+		}
+		return "", fmt.Errorf("%s: %s", strings.Join(cmd, " "), msg)
+	}
+	return stdout.String(), nil
+}
+
+type execSession struct {
+	cli  *Client
+	id   string
+	resp types.HijackedResponse
+}
+
+func (s *execSession) Read(p []byte) (int, error) {
+	return s.resp.Reader.Read(p)
+}
+
+func (s *execSession) Write(p []byte) (int, error) {
+	return s.resp.Conn.Write(p)
+}
+
+func (s *execSession) Close() error {
+	s.resp.Close()
+	return nil
+}
+
+func (s *execSession) Resize(ctx context.Context, width, height uint) error {
+	return s.cli.cli.ContainerExecResize(ctx, s.id, container.ResizeOptions{Width: width, Height: height})
+}
@@ -0,0 +1,26 @@
+package docker
+
+import "testing"
+
+func TestPickShell(t *testing.T) {
+	present := map[string]bool{"/bin/sh": true, "/bin/ash": true}
+	exists := func(path string) bool { return present[path] }
+
+	tests := []struct {
+		shells  []string
+		want    string
+		wantErr bool
+	}{
+		{[]string{"/bin/bash", "/bin/sh"}, "/bin/sh", false},
+		{[]string{"/bin/ash", "/bin/sh"}, "/bin/ash", false},
+		{[]string{"/bin/bash", "zsh"}, "zsh", false}, // not checkable, tried as is
+		{[]string{"/bin/bash"}, "", true},
+		{nil, "", true},
+	}
+	for _, tt := range tests {
+		got, err := pickShell(tt.shells, exists)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("pickShell(%v) = %q, %v; want %q, error %v", tt.shells, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// FileEntry is a file or directory in a container
+type FileEntry struct {
+	Name       string
+	Size       int64 // -1 if unknown; for directories read from an archive, the size of the files below
+	Mode       os.FileMode
+	ModTime    time.Time
+	LinkTarget string
+}
+
+func (e FileEntry) IsDir() bool {
+	return e.Mode.IsDir()
+}
+
+// StatPath describes a path in a container
+func (c *Client) StatPath(ctx context.Context, containerID, p string) (FileEntry, error) {
+	stat, err := c.cli.ContainerStatPath(ctx, containerID, p)
+	if err != nil {
+		return FileEntry{}, err
+	}
+	return FileEntry{
+		Name:       stat.Name,
+		Size:       stat.Size,
+		Mode:       stat.Mode,
+		ModTime:    stat.Mtime,
+		LinkTarget: stat.LinkTarget,
+	}, nil
+}
+
+// ListDir returns the entries of a directory in a container, directories
+// first. The API has no listing: running containers are asked with ls,
+// which is quick but gives no sizes. Only when the container can't run it,
+// because it is stopped or has no ls, are the entries read from the
+// directory's archive, which costs as much as copying it. ls follows
+// symlinks so that links to directories can be opened.
+func (c *Client) ListDir(ctx context.Context, containerID, dir string) ([]FileEntry, error) {
+	out, err := c.execQuiet(ctx, containerID, []string{"ls", "-1ApL", "--", dir})
+	if err == nil {
+		return parseLs(out), nil
+	}
+	if !missingCommand(err) && !notRunning(err) {
+		return nil, err
+	}
+
+	rc, _, err := c.cli.CopyFromContainer(ctx, containerID, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return listArchive(rc)
+}
+
+// notRunning reports whether an exec failed because the container is
+// stopped or paused
+func notRunning(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "is not running") || strings.Contains(msg, "is paused")
+}
+
+// parseLs reads the output of ls -1ApL, where directories end in a slash
+func parseLs(out string) []FileEntry {
+	var entries []FileEntry
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		e := FileEntry{Name: line, Size: -1}
+		if name, ok := strings.CutSuffix(line, "/"); ok {
+			e.Name = name
+			e.Mode = os.ModeDir | 0o755
+		}
+		entries = append(entries, e)
+	}
+	sortEntries(entries)
+	return entries
+}
+
+// listArchive collects the direct children of the directory a tar archive
+// was made from. The first entry is the directory itself.
+func listArchive(r io.Reader) ([]FileEntry, error) {
+	tr := tar.NewReader(r)
+	byName := map[string]*FileEntry{}
+	var order []string
+	root, first := "", true
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimSuffix(hdr.Name, "/")
+		if first {
+			root, first = name, false
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		child, _, nested := strings.Cut(rel, "/")
+		if child == "" {
+			continue
+		}
+
+		e, ok := byName[child]
+		if !ok {
+			e = &FileEntry{Name: child, Mode: os.ModeDir | 0o755}
+			byName[child] = e
+			order = append(order, child)
+		}
+		if nested {
+			if hdr.Typeflag == tar.TypeReg {
+				e.Size += hdr.Size
+			}
+			continue
+		}
+		e.Mode = hdr.FileInfo().Mode()
+		e.ModTime = hdr.ModTime
+		e.LinkTarget = hdr.Linkname
+		if hdr.Typeflag == tar.TypeReg {
+			e.Size = hdr.Size
+		}
+	}
+
+	entries := make([]FileEntry, 0, len(order))
+	for _, name := range order {
+		entries = append(entries, *byName[name])
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+func sortEntries(entries []FileEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+// DownloadPath copies a file or directory out of a container, like docker
+// cp: into dst if dst is an existing directory, otherwise to dst itself.
+// progress is called with the number of bytes written so far.
+func (c *Client) DownloadPath(ctx context.Context, containerID, src, dst string, progress func(int64)) error {
+	rc, _, err := c.cli.CopyFromContainer(ctx, containerID, src)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return extractArchive(rc, dst, progress)
+}
+
+// extractArchive unpacks a tar archive made from a single file or
+// directory. Entries that would land outside the destination are refused.
+func extractArchive(r io.Reader, dst string, progress func(int64)) error {
+	into := false
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		into = true
+	}
+
+	tr := tar.NewReader(r)
+	var written int64
+	root := ""
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(strings.TrimSuffix(hdr.Name, "/"))
+		if root == "" {
+			root = name
+		}
+		if name != root && !strings.HasPrefix(name, root+"/") {
+			return fmt.Errorf("unexpected archive entry %q", hdr.Name)
+		}
+
+		var target string
+		if into {
+			target = filepath.Join(dst, filepath.FromSlash(name))
+		} else {
+			target = filepath.Join(dst, filepath.FromSlash(strings.TrimPrefix(name, root)))
+		}
+		if err := checkInside(dst, into, target); err != nil {
+			return err
+		}
+
+		mode := hdr.FileInfo().Mode()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode.Perm()|0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			// Replace rather than write through a symlink
+			if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				_ = os.Remove(target)
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, &progressReader{r: tr, total: &written, fn: progress})
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// Created as is; checkInside keeps later entries from being
+			// written through it
+			_ = os.Remove(target)
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			// Devices, fifos and hard links are skipped, as docker cp does
+			// for unprivileged users
+		}
+	}
+}
+
+// checkInside makes sure target, once its parent's symlinks are resolved,
+// is within the destination
+func checkInside(dst string, into bool, target string) error {
+	base := dst
+	if !into {
+		base = filepath.Dir(dst)
+	}
+	base, err := filepath.EvalSymlinks(base)
+	if err != nil {
+		return err
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(target))
+	if err != nil {
+		// The parent directory is created by an earlier entry; if it is
+		// missing, the archive is out of order
+		return err
+	}
+	if parent != base && !strings.HasPrefix(parent, base+string(filepath.Separator)) {
+		return fmt.Errorf("archive entry %s escapes %s", target, dst)
+	}
+	return nil
+}
+
+// UploadFile copies a local file into a directory of a container.
+// progress is called with the number of bytes sent so far.
+func (c *Client) UploadFile(ctx context.Context, containerID, src, dstDir string, progress func(int64)) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", src)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeFileArchive(pw, f, info, progress))
+	}()
+	defer pr.Close()
+
+	return c.cli.CopyToContainer(ctx, containerID, dstDir, pr, container.CopyToContainerOptions{})
+}
+
+// writeFileArchive writes a tar archive holding a single file
+func writeFileArchive(w io.Writer, f io.Reader, info os.FileInfo, progress func(int64)) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	// Ownership is the container's business; keep the local user out
+	hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+
+	tw := tar.NewWriter(w)
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	var sent int64
+	if _, err := io.Copy(tw, &progressReader{r: f, total: &sent, fn: progress}); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// progressReader reports the running total of bytes read through it
+type progressReader struct {
+	r     io.Reader
+	total *int64
+	fn    func(int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		*p.total += int64(n)
+		if p.fn != nil {
+			p.fn(*p.total)
+		}
+	}
+	return n, err
+}
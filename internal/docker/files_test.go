@@ -0,0 +1,156 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tarEntry struct {
+	name, body, link string
+	dir              bool
+}
+
+func makeTar(t *testing.T, entries ...tarEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, ModTime: time.Unix(1700000000, 0), Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		switch {
+		case e.dir:
+			hdr.Typeflag, hdr.Mode, hdr.Size = tar.TypeDir, 0o755, 0
+		case e.link != "":
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.link, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Size > 0 {
+			tw.Write([]byte(e.body))
+		}
+	}
+	tw.Close()
+	return &buf
+}
+
+func TestListArchive(t *testing.T) {
+	archive := makeTar(t,
+		tarEntry{name: "etc/", dir: true},
+		tarEntry{name: "etc/hosts", body: "127.0.0.1 localhost\n"},
+		tarEntry{name: "etc/ssl/", dir: true},
+		tarEntry{name: "etc/ssl/cert.pem", body: strings.Repeat("x", 100)},
+		tarEntry{name: "etc/ssl/key.pem", body: strings.Repeat("y", 50)},
+		tarEntry{name: "etc/localtime", link: "/usr/share/zoneinfo/UTC"},
+	)
+
+	entries, err := listArchive(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name)
+	}
+	if strings.Join(got, " ") != "ssl hosts localtime" {
+		t.Fatalf("entries = %v, want directories first, then by name", got)
+	}
+	if !entries[0].IsDir() || entries[0].Size != 150 {
+		t.Errorf("ssl = %+v, want a directory of 150 bytes", entries[0])
+	}
+	if entries[1].Size != 20 {
+		t.Errorf("hosts size = %d, want 20", entries[1].Size)
+	}
+	if entries[2].LinkTarget != "/usr/share/zoneinfo/UTC" {
+		t.Errorf("localtime link = %q", entries[2].LinkTarget)
+	}
+}
+
+func TestParseLs(t *testing.T) {
+	entries := parseLs("passwd\nssl/\n.hidden\n")
+	if len(entries) != 3 || entries[0].Name != "ssl" || !entries[0].IsDir() || entries[1].Name != ".hidden" || entries[1].Size != -1 {
+		t.Fatalf("parseLs = %+v", entries)
+	}
+}
+
+func TestNotRunning(t *testing.T) {
+	for msg, want := range map[string]bool{
+		"Error response from daemon: container 3f2a is not running":                               true,
+		"Error response from daemon: container 3f2a is paused, unpause the container before exec": true,
+		"ls -1ApL -- /nope: ls: cannot access '/nope': No such file or directory":                 false,
+	} {
+		if got := notRunning(errors.New(msg)); got != want {
+			t.Errorf("notRunning(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := func() *bytes.Buffer {
+		return makeTar(t,
+			tarEntry{name: "conf/", dir: true},
+			tarEntry{name: "conf/app.yaml", body: "port: 80\n"},
+		)
+	}
+
+	// Into an existing directory
+	var progress int64
+	if err := extractArchive(archive(), dir, func(n int64) { progress = n }); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "conf", "app.yaml")); err != nil || string(data) != "port: 80\n" {
+		t.Fatalf("conf/app.yaml = %q, %v", data, err)
+	}
+	if progress != 9 {
+		t.Errorf("progress = %d, want 9", progress)
+	}
+
+	// To a new name
+	renamed := filepath.Join(dir, "copy")
+	if err := extractArchive(archive(), renamed, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(renamed, "app.yaml")); err != nil {
+		t.Errorf("copy/app.yaml: %v", err)
+	}
+
+	// Writing through a symlink out of the destination is refused
+	outside := t.TempDir()
+	evil := makeTar(t,
+		tarEntry{name: "x/", dir: true},
+		tarEntry{name: "x/out", link: outside},
+		tarEntry{name: "x/out/pwned", body: "boom"},
+	)
+	if err := extractArchive(evil, filepath.Join(dir, "evil"), nil); err == nil {
+		t.Error("archive writing through a symlink should fail")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "pwned")); err == nil {
+		t.Error("file was written outside of the destination")
+	}
+}
+
+func TestWriteFileArchive(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "seed.sql")
+	os.WriteFile(src, []byte("select 1;"), 0o600)
+	f, _ := os.Open(src)
+	defer f.Close()
+	info, _ := f.Stat()
+
+	var buf bytes.Buffer
+	if err := writeFileArchive(&buf, f, info, nil); err != nil {
+		t.Fatal(err)
+	}
+	hdr, err := tar.NewReader(&buf).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Name != "seed.sql" || hdr.Size != 9 || hdr.Uid != 0 || hdr.Uname != "" {
+		t.Errorf("header = %+v", hdr)
+	}
+}
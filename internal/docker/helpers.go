@@ -1,15 +1,79 @@
 package docker
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 )
 
+func toContainerInfo(cont types.Container) ContainerInfo {
+	name := ""
+	if len(cont.Names) > 0 {
+		name = strings.TrimPrefix(cont.Names[0], "/")
+	}
+
+	info := ContainerInfo{
+		ID:      cont.ID,
+		Name:    name,
+		Image:   cont.Image,
+		Status:  cont.Status,
+		State:   cont.State,
+		Ports:   formatPorts(cont.Ports),
+		Created: time.Unix(cont.Created, 0),
+	}
+	if uptime, ok := parseUptime(cont.Status); ok {
+		info.StartedAt = time.Now().Add(-uptime)
+	}
+	return info
+}
+
+// parseUptime reads the uptime out of a container status such as
+// "Up 2 hours (healthy)". The list API has no start time, and the daemon
+// renders the duration with go-units' HumanDuration, so the result is only
+// as precise as that text.
+func parseUptime(status string) (time.Duration, bool) {
+	rest, ok := strings.CutPrefix(status, "Up ")
+	if !ok {
+		return 0, false
+	}
+	if i := strings.Index(rest, " ("); i >= 0 {
+		rest = rest[:i]
+	}
+
+	switch rest {
+	case "Less than a second":
+		return 0, true
+	case "About a minute":
+		return time.Minute, true
+	case "About an hour":
+		return time.Hour, true
+	}
+
+	var n int
+	var unit string
+	if _, err := fmt.Sscanf(rest, "%d %s", &n, &unit); err != nil {
+		return 0, false
+	}
+
+	units := map[string]time.Duration{
+		"second": time.Second,
+		"minute": time.Minute,
+		"hour":   time.Hour,
+		"day":    24 * time.Hour,
+		"week":   7 * 24 * time.Hour,
+		"month":  30 * 24 * time.Hour,
+		"year":   365 * 24 * time.Hour,
+	}
+	d, ok := units[strings.TrimSuffix(unit, "s")]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(n) * d, true
+}
+
 func formatPorts(ports []types.Port) string {
 	if len(ports) == 0 {
 		return "-"
@@ -45,30 +109,71 @@ func calculateCPUPercent(stats *container.StatsResponse) float64 {
 	return 0.0
 }
 
-func decodeStats(reader io.Reader, stats *container.StatsResponse) error {
-	return json.NewDecoder(reader).Decode(stats)
+func statsToInfo(containerID string, stats *container.StatsResponse) *ContainerInfo {
+	cpuPercent := calculateCPUPercent(stats)
+	memUsage := stats.MemoryStats.Usage
+	memLimit := stats.MemoryStats.Limit
+	memPercent := 0.0
+	if memLimit > 0 {
+		memPercent = float64(memUsage) / float64(memLimit) * 100
+	}
+
+	var netRx, netTx uint64
+	for _, net := range stats.Networks {
+		netRx += net.RxBytes
+		netTx += net.TxBytes
+	}
+
+	// cgroup v1 reports "Read"/"Write", v2 lowercase
+	var blkRead, blkWrite uint64
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			blkRead += entry.Value
+		case "write":
+			blkWrite += entry.Value
+		}
+	}
+
+	return &ContainerInfo{
+		ID:         containerID,
+		CPUPerc:    cpuPercent,
+		MemUsage:   memUsage,
+		MemLimit:   memLimit,
+		MemPerc:    memPercent,
+		NetRx:      netRx,
+		NetTx:      netTx,
+		BlockRead:  blkRead,
+		BlockWrite: blkWrite,
+		PIDs:       stats.PidsStats.Current,
+		Sampled:    stats.Read,
+	}
 }
 
-func formatInt(n int) string {
-	return fmt.Sprintf("%d", n)
+// setRates fills in the I/O rates of cur from the cumulative counters of
+// the previous sample of the same container
+func setRates(cur *ContainerInfo, prev ContainerInfo) {
+	seconds := cur.Sampled.Sub(prev.Sampled).Seconds()
+	if prev.Sampled.IsZero() || seconds <= 0 {
+		return
+	}
+	cur.NetRxRate = counterRate(prev.NetRx, cur.NetRx, seconds)
+	cur.NetTxRate = counterRate(prev.NetTx, cur.NetTx, seconds)
+	cur.BlockReadRate = counterRate(prev.BlockRead, cur.BlockRead, seconds)
+	cur.BlockWriteRate = counterRate(prev.BlockWrite, cur.BlockWrite, seconds)
 }
 
-func stripLogHeaders(logs string) string {
-	lines := strings.Split(logs, "\n")
-	var result []string
-
-	for _, line := range lines {
-		if len(line) > 8 {
-			// Docker multiplexed log format has 8-byte header
-			// First byte indicates stream (1=stdout, 2=stderr)
-			// Bytes 5-8 indicate length
-			result = append(result, line[8:])
-		} else if len(line) > 0 {
-			result = append(result, line)
-		}
+// counterRate turns two readings of a cumulative counter into a per-second
+// rate. Counters reset when a container restarts; that interval reads as 0.
+func counterRate(prev, cur uint64, seconds float64) float64 {
+	if cur < prev {
+		return 0
 	}
+	return float64(cur-prev) / seconds
+}
 
-	return strings.Join(result, "\n")
+func formatInt(n int) string {
+	return fmt.Sprintf("%d", n)
 }
 
 // FormatBytes formats bytes into human readable format
@@ -0,0 +1,30 @@
+package docker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUptime(t *testing.T) {
+	tests := []struct {
+		status string
+		want   time.Duration
+		ok     bool
+	}{
+		{"Up Less than a second", 0, true},
+		{"Up 5 seconds", 5 * time.Second, true},
+		{"Up About a minute", time.Minute, true},
+		{"Up 2 hours (healthy)", 2 * time.Hour, true},
+		{"Up 1 day", 24 * time.Hour, true},
+		{"Up 3 weeks (Paused)", 3 * 7 * 24 * time.Hour, true},
+		{"Exited (0) 2 hours ago", 0, false},
+		{"Created", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseUptime(tt.status)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseUptime(%q) = %v, %v; want %v, %v", tt.status, got, ok, tt.want, tt.ok)
+		}
+	}
+}
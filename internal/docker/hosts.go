@@ -0,0 +1,286 @@
+package docker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/docker/docker/client"
+	"github.com/seb07-cloud/dktop/internal/config"
+)
+
+// Host describes a Docker engine endpoint
+type Host struct {
+	Name          string
+	Host          string // unix:///var/run/docker.sock, tcp://host:2376, ssh://user@host; empty means DOCKER_HOST / platform default
+	TLSCACert     string
+	TLSCert       string
+	TLSKey        string
+	TLSSkipVerify bool
+	Source        string // env, context or config
+}
+
+// Endpoint returns a display string for the host address
+func (h Host) Endpoint() string {
+	if h.Host != "" {
+		return h.Host
+	}
+	if env := os.Getenv(client.EnvOverrideHost); env != "" {
+		return env
+	}
+	return client.DefaultDockerHost
+}
+
+func (h Host) usesTLS() bool {
+	return h.TLSCACert != "" || h.TLSCert != "" || h.TLSKey != "" || h.TLSSkipVerify
+}
+
+func (h Host) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: h.TLSSkipVerify,
+	}
+
+	if h.TLSCACert != "" {
+		pem, err := os.ReadFile(h.TLSCACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", h.TLSCACert)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if h.TLSCert != "" || h.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(h.TLSCert, h.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// Connect creates a client for the given host. A host without an address
+// falls back to the environment, like NewClient.
+func Connect(h Host) (*Client, error) {
+	if h.Host == "" {
+		return NewClient()
+	}
+
+	u, err := url.Parse(h.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %q: %w", h.Host, err)
+	}
+
+	opts := []client.Opt{client.WithAPIVersionNegotiation()}
+
+	switch u.Scheme {
+	case "ssh":
+		// The remote docker CLI proxies the API over the ssh session; the
+		// HTTP host is a placeholder that is never resolved
+		opts = append(opts,
+			client.WithHost("http://docker.example.com"),
+			client.WithDialContext(sshDialer(u)),
+		)
+	default:
+		if h.usesTLS() {
+			tlsCfg, err := h.tlsConfig()
+			if err != nil {
+				return nil, err
+			}
+			// Keep the default proxy, timeout and keep-alive settings
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = tlsCfg
+			opts = append(opts, client.WithHTTPClient(&http.Client{Transport: transport}))
+		}
+		opts = append(opts, client.WithHost(h.Host))
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cli: cli}, nil
+}
+
+// dockerConfigDir returns the docker CLI config directory, honouring DOCKER_CONFIG
+func dockerConfigDir() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".docker"), nil
+}
+
+type contextMeta struct {
+	Name      string `json:"Name"`
+	Endpoints map[string]struct {
+		Host          string `json:"Host"`
+		SkipTLSVerify bool   `json:"SkipTLSVerify"`
+	} `json:"Endpoints"`
+}
+
+// ListContexts reads the Docker contexts stored by the docker CLI under
+// ~/.docker/contexts. The implicit "default" context is not stored there
+// and is not returned.
+func ListContexts() ([]Host, error) {
+	configDir, err := dockerConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	metaDir := filepath.Join(configDir, "contexts", "meta")
+	entries, err := os.ReadDir(metaDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var hosts []Host
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(metaDir, entry.Name(), "meta.json"))
+		if err != nil {
+			continue
+		}
+		var meta contextMeta
+		if err := json.Unmarshal(data, &meta); err != nil {
+			continue
+		}
+		endpoint, ok := meta.Endpoints["docker"]
+		if !ok || meta.Name == "" {
+			continue
+		}
+
+		h := Host{
+			Name:          meta.Name,
+			Host:          endpoint.Host,
+			TLSSkipVerify: endpoint.SkipTLSVerify,
+			Source:        "context",
+		}
+
+		// TLS material lives in a directory named after the same digest
+		tlsDir := filepath.Join(configDir, "contexts", "tls", entry.Name(), "docker")
+		if path := filepath.Join(tlsDir, "ca.pem"); fileExists(path) {
+			h.TLSCACert = path
+		}
+		if path := filepath.Join(tlsDir, "cert.pem"); fileExists(path) {
+			h.TLSCert = path
+		}
+		if path := filepath.Join(tlsDir, "key.pem"); fileExists(path) {
+			h.TLSKey = path
+		}
+
+		hosts = append(hosts, h)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	return hosts, nil
+}
+
+// CurrentContext returns the context selected with `docker context use`,
+// or "default" if none is
+func CurrentContext() string {
+	configDir, err := dockerConfigDir()
+	if err != nil {
+		return "default"
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		return "default"
+	}
+
+	var cfg struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil || cfg.CurrentContext == "" {
+		return "default"
+	}
+	return cfg.CurrentContext
+}
+
+// AvailableHosts lists the environment default, the hosts declared in the
+// config and the Docker CLI contexts. Config entries shadow contexts with
+// the same name.
+func AvailableHosts(cfg *config.Config) []Host {
+	hosts := []Host{{Name: "default", Source: "env"}}
+	seen := map[string]bool{"default": true}
+
+	for _, hc := range cfg.Hosts {
+		if hc.Name == "" || seen[hc.Name] {
+			continue
+		}
+		seen[hc.Name] = true
+		hosts = append(hosts, Host{
+			Name:          hc.Name,
+			Host:          hc.Host,
+			TLSCACert:     hc.TLSCACert,
+			TLSCert:       hc.TLSCert,
+			TLSKey:        hc.TLSKey,
+			TLSSkipVerify: hc.TLSSkipVerify,
+			Source:        "config",
+		})
+	}
+
+	contexts, _ := ListContexts()
+	for _, h := range contexts {
+		if seen[h.Name] {
+			continue
+		}
+		seen[h.Name] = true
+		hosts = append(hosts, h)
+	}
+
+	return hosts
+}
+
+// StartupHost picks the host to connect to at launch: the configured host,
+// otherwise DOCKER_HOST, otherwise the active Docker context, mirroring the
+// precedence of the docker CLI. A configured host that is neither in the
+// config's hosts nor a context is an error rather than a silent fallback.
+func StartupHost(cfg *config.Config) (Host, error) {
+	hosts := AvailableHosts(cfg)
+
+	name := cfg.Host
+	if name == "" && os.Getenv(client.EnvOverrideHost) == "" {
+		name = os.Getenv("DOCKER_CONTEXT")
+		if name == "" {
+			name = CurrentContext()
+		}
+	}
+
+	for _, h := range hosts {
+		if h.Name == name {
+			return h, nil
+		}
+	}
+	if cfg.Host != "" {
+		return Host{}, fmt.Errorf("unknown host %q: not in the config's hosts or the Docker contexts", cfg.Host)
+	}
+	return hosts[0], nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
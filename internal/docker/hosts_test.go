@@ -0,0 +1,26 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/seb07-cloud/dktop/internal/config"
+)
+
+func TestStartupHost(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+	cfg := &config.Config{Hosts: []config.HostConfig{{Name: "build", Host: "tcp://build:2376"}}}
+
+	if h, err := StartupHost(cfg); err != nil || h.Name != "default" {
+		t.Errorf("no host configured: %+v, %v", h, err)
+	}
+	cfg.Host = "build"
+	if h, err := StartupHost(cfg); err != nil || h.Host != "tcp://build:2376" {
+		t.Errorf("host build: %+v, %v", h, err)
+	}
+	cfg.Host = "biuld"
+	if h, err := StartupHost(cfg); err == nil {
+		t.Errorf("unknown host connected to %+v", h)
+	}
+}
@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+)
+
+// ImageLayer is one step of an image's build history. Steps that only
+// change metadata, such as ENV or CMD, have no size.
+type ImageLayer struct {
+	ID        string // "<missing>" for steps built on another machine
+	CreatedBy string
+	Created   time.Time
+	Size      int64
+	Comment   string
+}
+
+// ImageDetail is an image's configuration together with its history
+type ImageDetail struct {
+	ID           string
+	Tags         []string
+	Digests      []string
+	Created      time.Time
+	Size         int64
+	Architecture string
+	Variant      string
+	OS           string
+	Author       string
+	Entrypoint   []string
+	Cmd          []string
+	Env          []string
+	ExposedPorts []string
+	Labels       map[string]string
+	WorkingDir   string
+	User         string
+	Layers       []ImageLayer // newest first, like docker history
+}
+
+// InspectImage returns the configuration and build history of an image
+func (c *Client) InspectImage(ctx context.Context, ref string) (*ImageDetail, error) {
+	info, _, err := c.cli.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	history, err := c.cli.ImageHistory(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return newImageDetail(info, history), nil
+}
+
+func newImageDetail(info types.ImageInspect, history []image.HistoryResponseItem) *ImageDetail {
+	d := &ImageDetail{
+		ID:           info.ID,
+		Tags:         info.RepoTags,
+		Digests:      info.RepoDigests,
+		Size:         info.Size,
+		Architecture: info.Architecture,
+		Variant:      info.Variant,
+		OS:           info.Os,
+		Author:       info.Author,
+	}
+	d.Created, _ = time.Parse(time.RFC3339Nano, info.Created)
+	if cfg := info.Config; cfg != nil {
+		d.Entrypoint = cfg.Entrypoint
+		d.Cmd = cfg.Cmd
+		d.Env = cfg.Env
+		d.Labels = cfg.Labels
+		d.WorkingDir = cfg.WorkingDir
+		d.User = cfg.User
+		for port := range cfg.ExposedPorts {
+			d.ExposedPorts = append(d.ExposedPorts, string(port))
+		}
+		sort.Strings(d.ExposedPorts)
+	}
+
+	for _, h := range history {
+		d.Layers = append(d.Layers, ImageLayer{
+			ID:        h.ID,
+			CreatedBy: CleanCreatedBy(h.CreatedBy),
+			Created:   time.Unix(h.Created, 0),
+			Size:      h.Size,
+			Comment:   h.Comment,
+		})
+	}
+	return d
+}
+
+// CleanCreatedBy turns a history entry's command into the Dockerfile
+// instruction it came from. The classic builder records metadata steps
+// as `/bin/sh -c #(nop)  CMD ["nginx"]` and RUN steps as `/bin/sh -c ...`;
+// BuildKit records `RUN /bin/sh -c ...`.
+func CleanCreatedBy(createdBy string) string {
+	s := strings.Join(strings.Fields(createdBy), " ")
+	if rest, ok := strings.CutPrefix(s, "/bin/sh -c #(nop) "); ok {
+		return rest
+	}
+	if rest, ok := strings.CutPrefix(s, "/bin/sh -c "); ok {
+		return "RUN " + rest
+	}
+	if rest, ok := strings.CutPrefix(s, "RUN /bin/sh -c "); ok {
+		return "RUN " + rest
+	}
+	return s
+}
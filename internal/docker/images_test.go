@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestCleanCreatedBy(t *testing.T) {
+	for in, want := range map[string]string{
+		`/bin/sh -c #(nop)  CMD ["nginx" "-g" "daemon off;"]`:     `CMD ["nginx" "-g" "daemon off;"]`,
+		`/bin/sh -c #(nop) ADD file:abc in / `:                    `ADD file:abc in /`,
+		"/bin/sh -c apt-get update \t&&  apt-get install -y curl": "RUN apt-get update && apt-get install -y curl",
+		"RUN /bin/sh -c npm ci # buildkit":                        "RUN npm ci # buildkit",
+		`ENTRYPOINT ["/docker-entrypoint.sh"]`:                    `ENTRYPOINT ["/docker-entrypoint.sh"]`,
+	} {
+		if got := CleanCreatedBy(in); got != want {
+			t.Errorf("CleanCreatedBy(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNewImageDetail(t *testing.T) {
+	d := newImageDetail(types.ImageInspect{
+		ID:           "sha256:abc",
+		RepoTags:     []string{"nginx:latest"},
+		Created:      "2024-05-01T10:00:00.123456789Z",
+		Architecture: "arm64",
+		Variant:      "v8",
+		Os:           "linux",
+		Config: &container.Config{
+			Cmd:          []string{"nginx", "-g", "daemon off;"},
+			ExposedPorts: nat.PortSet{"80/tcp": {}, "443/tcp": {}},
+		},
+	}, []image.HistoryResponseItem{
+		{ID: "sha256:abc", CreatedBy: `/bin/sh -c #(nop)  CMD ["nginx"]`, Created: 1714557600},
+		{ID: "<missing>", CreatedBy: "/bin/sh -c apt-get install -y nginx", Size: 50 << 20},
+	})
+
+	if d.Created.Year() != 2024 || d.Architecture != "arm64" || len(d.Cmd) != 3 {
+		t.Errorf("detail = %+v", d)
+	}
+	if len(d.ExposedPorts) != 2 || d.ExposedPorts[0] != "443/tcp" {
+		t.Errorf("exposed ports = %v, want them sorted", d.ExposedPorts)
+	}
+	if len(d.Layers) != 2 || d.Layers[0].CreatedBy != `CMD ["nginx"]` || d.Layers[1].Size != 50<<20 {
+		t.Errorf("layers = %+v", d.Layers)
+	}
+}
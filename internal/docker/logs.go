@@ -0,0 +1,171 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LogStream identifies the output stream a log line was written to
+type LogStream uint8
+
+// Stream identifiers as used in the Docker multiplexed stream header
+const (
+	Stdout LogStream = 1
+	Stderr LogStream = 2
+
+	systemErr LogStream = 3
+)
+
+func (s LogStream) String() string {
+	switch s {
+	case Stdout:
+		return "stdout"
+	case Stderr:
+		return "stderr"
+	default:
+		return "unknown"
+	}
+}
+
+// LogLine is a single line of container output
+type LogLine struct {
+	Stream LogStream
+	Text   string
+}
+
+// frameHeaderLen is the size of a multiplexed stream frame header:
+// [stream, 0, 0, 0, size (uint32 big endian)]
+const frameHeaderLen = 8
+
+// maxFrameLen bounds the size a frame header may announce. The daemon
+// writes frames of at most a few hundred KiB; anything near the 4 GiB a
+// header can hold means the stream is corrupt.
+const maxFrameLen = 64 << 20
+
+// frameChunkLen is how much of a frame is read at a time, so a large frame
+// doesn't need a buffer of its size
+const frameChunkLen = 32 << 10
+
+// maxLineLen bounds a buffered partial line. Output that never ends its
+// lines, like a progress bar redrawn with \r, is reported in pieces once it
+// grows past this.
+const maxLineLen = 64 << 10
+
+// demuxLogs splits a container log stream into lines and calls fn for each
+// of them. Containers without a TTY produce a multiplexed stream made of
+// framed chunks; a chunk can hold several lines or part of one, so partial
+// lines are buffered per stream until their newline arrives. TTY containers
+// produce raw output, which is all reported as stdout.
+func demuxLogs(r io.Reader, tty bool, fn func(LogLine)) error {
+	if tty {
+		return splitLines(r, Stdout, fn)
+	}
+
+	partial := map[LogStream]*bytes.Buffer{
+		Stdout: {},
+		Stderr: {},
+	}
+	flush := func() {
+		for _, stream := range []LogStream{Stdout, Stderr} {
+			if buf := partial[stream]; buf.Len() > 0 {
+				fn(LogLine{Stream: stream, Text: trimLine(buf.String())})
+				buf.Reset()
+			}
+		}
+	}
+
+	header := make([]byte, frameHeaderLen)
+	chunk := make([]byte, frameChunkLen)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			flush()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("truncated log frame header: %w", err)
+			}
+			return err
+		}
+
+		stream := LogStream(header[0])
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+
+		switch stream {
+		case Stdout, Stderr:
+		case systemErr:
+			flush()
+			msg, _ := io.ReadAll(io.LimitReader(r, min(size, frameChunkLen)))
+			return fmt.Errorf("error from daemon in stream: %s", msg)
+		default:
+			// stdin is never echoed back; anything else means we've lost
+			// track of the framing, so treat the rest as raw output
+			flush()
+			return splitLines(io.MultiReader(bytes.NewReader(header), r), Stdout, fn)
+		}
+		if size > maxFrameLen {
+			flush()
+			return fmt.Errorf("corrupt log stream: frame of %d bytes", size)
+		}
+
+		buf := partial[stream]
+		for size > 0 {
+			n := min(size, frameChunkLen)
+			payload := chunk[:n]
+			if _, err := io.ReadFull(r, payload); err != nil {
+				flush()
+				return fmt.Errorf("truncated log frame: %w", err)
+			}
+			size -= n
+			appendLines(buf, payload, stream, fn)
+		}
+	}
+}
+
+// splitLines reports raw output line by line
+func splitLines(r io.Reader, stream LogStream, fn func(LogLine)) error {
+	var buf bytes.Buffer
+	chunk := make([]byte, frameChunkLen)
+	for {
+		n, err := r.Read(chunk)
+		appendLines(&buf, chunk[:n], stream, fn)
+		if err != nil {
+			if buf.Len() > 0 {
+				fn(LogLine{Stream: stream, Text: trimLine(buf.String())})
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// appendLines adds data to the partial line in buf and reports each line it
+// completes. A partial line longer than maxLineLen is reported as it stands.
+func appendLines(buf *bytes.Buffer, data []byte, stream LogStream, fn func(LogLine)) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			buf.Write(data)
+			if buf.Len() >= maxLineLen {
+				fn(LogLine{Stream: stream, Text: trimLine(buf.String())})
+				buf.Reset()
+			}
+			return
+		}
+		buf.Write(data[:i])
+		fn(LogLine{Stream: stream, Text: trimLine(buf.String())})
+		buf.Reset()
+		data = data[i+1:]
+	}
+}
+
+// trimLine drops the carriage return TTYs emit before each newline
+func trimLine(s string) string {
+	return strings.TrimSuffix(s, "\r")
+}
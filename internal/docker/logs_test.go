@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func frame(stream LogStream, payload string) []byte {
+	header := make([]byte, frameHeaderLen)
+	header[0] = byte(stream)
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func demuxAll(t *testing.T, data []byte, tty bool) []LogLine {
+	t.Helper()
+	var lines []LogLine
+	if err := demuxLogs(bytes.NewReader(data), tty, func(l LogLine) {
+		lines = append(lines, l)
+	}); err != nil {
+		t.Fatalf("demuxLogs: %v", err)
+	}
+	return lines
+}
+
+func TestDemuxLogs(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames [][]byte
+		want   []LogLine
+	}{
+		{
+			name:   "several lines in one frame",
+			frames: [][]byte{frame(Stdout, "one\ntwo\nthree\n")},
+			want: []LogLine{
+				{Stdout, "one"},
+				{Stdout, "two"},
+				{Stdout, "three"},
+			},
+		},
+		{
+			name: "line split across frames",
+			frames: [][]byte{
+				frame(Stdout, "hel"),
+				frame(Stdout, "lo wor"),
+				frame(Stdout, "ld\n"),
+			},
+			want: []LogLine{{Stdout, "hello world"}},
+		},
+		{
+			name: "interleaved streams keep their partial lines apart",
+			frames: [][]byte{
+				frame(Stdout, "out "),
+				frame(Stderr, "err line\n"),
+				frame(Stdout, "line\n"),
+			},
+			want: []LogLine{
+				{Stderr, "err line"},
+				{Stdout, "out line"},
+			},
+		},
+		{
+			name:   "trailing partial line is flushed",
+			frames: [][]byte{frame(Stderr, "no newline")},
+			want:   []LogLine{{Stderr, "no newline"}},
+		},
+		{
+			name:   "payload that looks like a header is not cut",
+			frames: [][]byte{frame(Stdout, "\x01\x00\x00\x00\x00\x00\x00\x05abc\n")},
+			want:   []LogLine{{Stdout, "\x01\x00\x00\x00\x00\x00\x00\x05abc"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := demuxAll(t, bytes.Join(tt.frames, nil), false)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDemuxLogsTTY(t *testing.T) {
+	got := demuxAll(t, []byte("prompt$ ls\r\nfile\r\npartial"), true)
+	want := []LogLine{
+		{Stdout, "prompt$ ls"},
+		{Stdout, "file"},
+		{Stdout, "partial"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDemuxLogsSystemError(t *testing.T) {
+	data := append(frame(Stdout, "ok\n"), frame(systemErr, "log driver failed")...)
+
+	var lines []LogLine
+	err := demuxLogs(bytes.NewReader(data), false, func(l LogLine) {
+		lines = append(lines, l)
+	})
+	if err == nil || !strings.Contains(err.Error(), "log driver failed") {
+		t.Fatalf("err = %v, want daemon error", err)
+	}
+	if len(lines) != 1 || lines[0].Text != "ok" {
+		t.Errorf("lines before the error = %q", lines)
+	}
+}
+
+func TestDemuxLogsTruncatedFrame(t *testing.T) {
+	data := frame(Stdout, "complete\n")
+	data = append(data, frame(Stdout, "cut short")[:12]...)
+
+	err := demuxLogs(bytes.NewReader(data), false, func(LogLine) {})
+	if err == nil {
+		t.Fatal("expected an error for a truncated frame")
+	}
+}
+
+func TestDemuxLogsLargeAndCorruptFrames(t *testing.T) {
+	long := strings.Repeat("x", 3*frameChunkLen/2)
+	got := demuxAll(t, frame(Stderr, "a\n"+long+"\nb\n"), false)
+	if len(got) != 3 || got[1].Text != long || got[2].Text != "b" {
+		t.Errorf("frame larger than a chunk split into %d lines", len(got))
+	}
+
+	// A stdout header announcing 4 GiB, e.g. a TTY stream read as framed
+	corrupt := []byte{byte(Stdout), 0, 0, 0, 0xff, 0xff, 0xff, 0xff}
+	err := demuxLogs(bytes.NewReader(corrupt), false, func(LogLine) {})
+	if err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("err = %v, want a corrupt stream error", err)
+	}
+}
+
+func TestDemuxLogsUnterminatedOutput(t *testing.T) {
+	// A progress bar that only ever redraws itself
+	bar := strings.Repeat("[=====>    ] 50%\r", maxLineLen/8)
+	for _, tty := range []bool{false, true} {
+		data := []byte(bar)
+		if !tty {
+			data = nil
+			for i := 0; i < len(bar); i += frameChunkLen {
+				data = append(data, frame(Stdout, bar[i:min(i+frameChunkLen, len(bar))])...)
+			}
+		}
+		got := demuxAll(t, data, tty)
+		if len(got) < 2 {
+			t.Fatalf("tty=%v: %d lines, want the output in pieces", tty, len(got))
+		}
+		total := 0
+		for _, l := range got {
+			if len(l.Text) > maxLineLen+frameChunkLen {
+				t.Errorf("tty=%v: line of %d bytes", tty, len(l.Text))
+			}
+			total += len(l.Text)
+		}
+		if total < len(bar)-len(got) {
+			t.Errorf("tty=%v: %d of %d bytes reported", tty, total, len(bar))
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ProgressMessage is one progress message of an image pull or push. ID
+// names the layer it is about, if any.
+type ProgressMessage struct {
+	ID      string
+	Status  string
+	Current int64
+	Total   int64
+}
+
+// progressJSON is a message as the daemon streams it
+type progressJSON struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+	Error string `json:"error"`
+}
+
+// ReadProgress decodes a pull or push stream, calling fn for every message.
+// The daemon reports failures, e.g. a denied registry login, inside the
+// stream; such an error is returned.
+func ReadProgress(r io.Reader, fn func(ProgressMessage)) error {
+	dec := json.NewDecoder(r)
+	for {
+		var m progressJSON
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if m.ErrorDetail != nil && m.ErrorDetail.Message != "" {
+			return errors.New(m.ErrorDetail.Message)
+		}
+		if m.Error != "" {
+			return errors.New(m.Error)
+		}
+		fn(ProgressMessage{
+			ID:      m.ID,
+			Status:  m.Status,
+			Current: m.ProgressDetail.Current,
+			Total:   m.ProgressDetail.Total,
+		})
+	}
+}
+
+// LayerProgress is the state of one layer of a pull or push. Current and
+// Total are the progress of its current step; Size and Transferred count
+// only the download or upload.
+type LayerProgress struct {
+	ID          string
+	Status      string
+	Current     int64
+	Total       int64
+	Size        int64
+	Transferred int64
+	Done        bool
+}
+
+// Progress follows a pull or push from its progress messages
+type Progress struct {
+	Layers []*LayerProgress // in the order the daemon first mentioned them
+	Status string           // latest message not about a layer, e.g. the digest
+
+	byID map[string]*LayerProgress
+}
+
+// Apply updates the progress with the next message
+func (p *Progress) Apply(m ProgressMessage) {
+	if m.ID == "" || isRefStatus(m.Status) {
+		// A push ends with an aux message that has no status; keep the
+		// digest line before it
+		if m.Status != "" {
+			p.Status = m.Status
+		}
+		return
+	}
+	if p.byID == nil {
+		p.byID = map[string]*LayerProgress{}
+	}
+	l, ok := p.byID[m.ID]
+	if !ok {
+		l = &LayerProgress{ID: m.ID}
+		p.byID[m.ID] = l
+		p.Layers = append(p.Layers, l)
+	}
+
+	l.Status = m.Status
+	l.Current, l.Total = m.Current, m.Total
+	switch m.Status {
+	case "Downloading", "Pushing":
+		if m.Total > 0 {
+			l.Size = m.Total
+		}
+		l.Transferred = m.Current
+	case "Verifying Checksum", "Download complete", "Extracting":
+		l.Transferred = l.Size
+	case "Pull complete", "Pushed":
+		l.Transferred = l.Size
+		l.Done = true
+	case "Already exists", "Layer already exists":
+		l.Done = true
+	}
+	if strings.HasPrefix(m.Status, "Mounted from") {
+		l.Done = true
+	}
+}
+
+// isRefStatus reports whether a message with an ID is about the tag being
+// pulled rather than a layer: the daemon sends "Pulling from library/nginx"
+// with the tag as its ID
+func isRefStatus(status string) bool {
+	return strings.HasPrefix(status, "Pulling from ")
+}
+
+// Bytes returns how much of the layers' download or upload is done, and
+// the size of the layers whose size is known so far
+func (p *Progress) Bytes() (done, total int64) {
+	for _, l := range p.Layers {
+		done += l.Transferred
+		total += l.Size
+	}
+	return done, total
+}
+
+// Complete reports whether every layer is done
+func (p *Progress) Complete() bool {
+	for _, l := range p.Layers {
+		if !l.Done {
+			return false
+		}
+	}
+	return true
+}
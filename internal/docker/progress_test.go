@@ -0,0 +1,88 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadProgress(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"status":"Pulling from library/nginx","id":"latest"}`,
+		`{"status":"Already exists","progressDetail":{},"id":"aaa"}`,
+		`{"status":"Pulling fs layer","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Pulling fs layer","progressDetail":{},"id":"ccc"}`,
+		`{"status":"Downloading","progressDetail":{"current":300,"total":1000},"progress":"[=>  ]","id":"bbb"}`,
+		`{"status":"Downloading","progressDetail":{"current":50,"total":200},"id":"ccc"}`,
+		`{"status":"Download complete","progressDetail":{},"id":"ccc"}`,
+		`{"status":"Extracting","progressDetail":{"current":100,"total":200},"id":"ccc"}`,
+	}, "\n")
+
+	var p Progress
+	if err := ReadProgress(strings.NewReader(stream), p.Apply); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != "Pulling from library/nginx" || len(p.Layers) != 3 {
+		t.Fatalf("progress = %+v", p)
+	}
+	if done, total := p.Bytes(); done != 500 || total != 1200 {
+		t.Errorf("Bytes() = %d/%d, want 500/1200", done, total)
+	}
+	if ccc := p.Layers[2]; ccc.Status != "Extracting" || ccc.Current != 100 || ccc.Total != 200 {
+		t.Errorf("ccc = %+v", ccc)
+	}
+	if p.Complete() {
+		t.Error("pull reported complete")
+	}
+
+	for _, l := range []string{
+		`{"status":"Pull complete","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Pull complete","progressDetail":{},"id":"ccc"}`,
+		`{"status":"Digest: sha256:abc"}`,
+	} {
+		if err := ReadProgress(strings.NewReader(l), p.Apply); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !p.Complete() || p.Status != "Digest: sha256:abc" {
+		t.Errorf("after completion: %+v", p)
+	}
+	if done, total := p.Bytes(); done != total {
+		t.Errorf("Bytes() = %d/%d after completion", done, total)
+	}
+}
+
+func TestReadPushProgress(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"status":"The push refers to repository [localhost:5000/app]"}`,
+		`{"status":"Preparing","progressDetail":{},"id":"aaa"}`,
+		`{"status":"Preparing","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Mounted from library/alpine","progressDetail":{},"id":"aaa"}`,
+		`{"status":"Pushing","progressDetail":{"current":512,"total":2048},"id":"bbb"}`,
+		`{"status":"Pushed","progressDetail":{},"id":"bbb"}`,
+		`{"status":"1.0: digest: sha256:abc size: 739"}`,
+		`{"progressDetail":{},"aux":{"Tag":"1.0","Digest":"sha256:abc","Size":739}}`,
+	}, "\n")
+
+	var p Progress
+	if err := ReadProgress(strings.NewReader(stream), p.Apply); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Complete() || p.Status != "1.0: digest: sha256:abc size: 739" {
+		t.Errorf("progress = %+v", p)
+	}
+	if done, total := p.Bytes(); done != 2048 || total != 2048 {
+		t.Errorf("Bytes() = %d/%d, want 2048/2048", done, total)
+	}
+}
+
+func TestReadProgressReturnsStreamError(t *testing.T) {
+	stream := `{"status":"Pulling from private/app","id":"1.0"}
+{"errorDetail":{"message":"pull access denied for private/app"},"error":"pull access denied for private/app"}
+{"status":"never read"}`
+
+	var seen int
+	err := ReadProgress(strings.NewReader(stream), func(ProgressMessage) { seen++ })
+	if err == nil || err.Error() != "pull access denied for private/app" || seen != 1 {
+		t.Errorf("err = %v after %d messages", err, seen)
+	}
+}
@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// RunOptions describes a container to create, in docker run terms
+type RunOptions struct {
+	Image      string
+	Name       string   // empty for a generated name
+	Cmd        []string // empty for the image's default command
+	Env        []string // KEY=value
+	Ports      []string // [ip:]host:container[/proto], or a container port to publish on a random one
+	Volumes    []string // host-path-or-volume:container-path[:ro]
+	Network    string
+	Restart    string // no, always, unless-stopped, on-failure[:max-retries]
+	AutoRemove bool
+}
+
+// CreateContainer creates a container from opts without starting it and
+// returns its ID
+func (c *Client) CreateContainer(ctx context.Context, opts RunOptions) (string, error) {
+	cfg, hostCfg, err := runConfig(opts)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.cli.ContainerCreate(ctx, cfg, hostCfg, nil, nil, opts.Name)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// runConfig turns opts into the create request, rejecting what the daemon
+// would reject with a less helpful message
+func runConfig(opts RunOptions) (*container.Config, *container.HostConfig, error) {
+	if opts.Image == "" {
+		return nil, nil, errors.New("no image given")
+	}
+
+	exposed, bindings, err := nat.ParsePortSpecs(opts.Ports)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, bind := range opts.Volumes {
+		if parts := strings.Split(bind, ":"); len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, nil, fmt.Errorf("invalid volume %q, expected source:target[:ro]", bind)
+		}
+	}
+	for _, env := range opts.Env {
+		if strings.HasPrefix(env, "=") {
+			return nil, nil, fmt.Errorf("invalid environment variable %q", env)
+		}
+	}
+	restart, err := parseRestartPolicy(opts.Restart)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.AutoRemove && !restart.IsNone() {
+		return nil, nil, errors.New("auto-remove can't be combined with a restart policy")
+	}
+
+	cfg := &container.Config{
+		Image:        opts.Image,
+		Cmd:          opts.Cmd,
+		Env:          opts.Env,
+		ExposedPorts: exposed,
+	}
+	hostCfg := &container.HostConfig{
+		Binds:         opts.Volumes,
+		PortBindings:  bindings,
+		NetworkMode:   container.NetworkMode(opts.Network),
+		RestartPolicy: restart,
+		AutoRemove:    opts.AutoRemove,
+	}
+	return cfg, hostCfg, nil
+}
+
+// parseRestartPolicy reads a policy as docker run --restart takes it
+func parseRestartPolicy(s string) (container.RestartPolicy, error) {
+	if s == "" {
+		return container.RestartPolicy{Name: container.RestartPolicyDisabled}, nil
+	}
+	name, count, hasCount := strings.Cut(s, ":")
+	policy := container.RestartPolicy{Name: container.RestartPolicyMode(name)}
+	if hasCount {
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return policy, fmt.Errorf("invalid restart policy %q", s)
+		}
+		policy.MaximumRetryCount = n
+	}
+	if err := container.ValidateRestartPolicy(policy); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
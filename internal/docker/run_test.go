@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestRunConfig(t *testing.T) {
+	cfg, hostCfg, err := runConfig(RunOptions{
+		Image:   "nginx:latest",
+		Cmd:     []string{"nginx", "-g", "daemon off;"},
+		Env:     []string{"MODE=prod"},
+		Ports:   []string{"8080:80", "127.0.0.1:8443:443/tcp", "9000"},
+		Volumes: []string{"/srv/www:/usr/share/nginx/html:ro"},
+		Network: "frontend",
+		Restart: "on-failure:3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Image != "nginx:latest" || len(cfg.Cmd) != 3 || cfg.Env[0] != "MODE=prod" {
+		t.Errorf("config = %+v", cfg)
+	}
+	for _, port := range []nat.Port{"80/tcp", "443/tcp", "9000/tcp"} {
+		if _, ok := cfg.ExposedPorts[port]; !ok {
+			t.Errorf("%s not exposed: %v", port, cfg.ExposedPorts)
+		}
+	}
+	if b := hostCfg.PortBindings["443/tcp"]; len(b) != 1 || b[0].HostIP != "127.0.0.1" || b[0].HostPort != "8443" {
+		t.Errorf("443 bound to %+v", b)
+	}
+	if b := hostCfg.PortBindings["9000/tcp"]; len(b) != 1 || b[0].HostPort != "" {
+		t.Errorf("9000 should be published on a random port, got %+v", b)
+	}
+	if hostCfg.NetworkMode != "frontend" || hostCfg.Binds[0] != "/srv/www:/usr/share/nginx/html:ro" {
+		t.Errorf("host config = %+v", hostCfg)
+	}
+	if hostCfg.RestartPolicy.Name != container.RestartPolicyOnFailure || hostCfg.RestartPolicy.MaximumRetryCount != 3 {
+		t.Errorf("restart policy = %+v", hostCfg.RestartPolicy)
+	}
+}
+
+func TestRunConfigRejects(t *testing.T) {
+	for name, opts := range map[string]RunOptions{
+		"no image":            {},
+		"bad port":            {Image: "x", Ports: []string{"80:http"}},
+		"bad volume":          {Image: "x", Volumes: []string{"/data"}},
+		"bad restart":         {Image: "x", Restart: "sometimes"},
+		"retries with always": {Image: "x", Restart: "always:2"},
+		"auto-remove restart": {Image: "x", Restart: "always", AutoRemove: true},
+		"env without name":    {Image: "x", Env: []string{"=value"}},
+	} {
+		if _, _, err := runConfig(opts); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	if _, hostCfg, err := runConfig(RunOptions{Image: "x", AutoRemove: true}); err != nil || !hostCfg.AutoRemove {
+		t.Errorf("auto-remove without a restart policy: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Runtime is the container engine API the UI and the daemon are written
+// against. *Client implements it for a real Docker daemon; tests use the
+// in-memory fake from the dockertest package.
+type Runtime interface {
+	Ping(ctx context.Context) error
+	Close() error
+
+	// Containers
+	ListContainers(ctx context.Context) ([]ContainerInfo, error)
+	GetContainer(ctx context.Context, containerID string) (*ContainerInfo, error)
+	GetContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	GetContainerDiff(ctx context.Context, containerID string) (FilesystemDiff, error)
+	CreateContainer(ctx context.Context, opts RunOptions) (string, error)
+	StartContainer(ctx context.Context, containerID string) error
+	StopContainer(ctx context.Context, containerID string, timeout time.Duration) error
+	RestartContainer(ctx context.Context, containerID string, timeout time.Duration) error
+	KillContainer(ctx context.Context, containerID, signal string) error
+	PauseContainer(ctx context.Context, containerID string) error
+	UnpauseContainer(ctx context.Context, containerID string) error
+	RemoveContainer(ctx context.Context, containerID string, force, removeVolumes bool) error
+	SetRestartPolicy(ctx context.Context, containerID string, policy string) error
+
+	// Container processes
+	ExecShell(ctx context.Context, containerID string, opts ExecOptions) (ExecSession, error)
+	ListProcesses(ctx context.Context, containerID string) ([]ProcessInfo, error)
+	SignalProcess(ctx context.Context, containerID string, pid int, signal string) error
+
+	// Container files
+	StatPath(ctx context.Context, containerID, path string) (FileEntry, error)
+	ListDir(ctx context.Context, containerID, dir string) ([]FileEntry, error)
+	DownloadPath(ctx context.Context, containerID, src, dst string, progress func(int64)) error
+	UploadFile(ctx context.Context, containerID, src, dstDir string, progress func(int64)) error
+
+	// Stats
+	StreamContainerStats(ctx context.Context, containerID string) (<-chan ContainerInfo, <-chan error)
+	GetSystemStats(ctx context.Context) (*SystemStats, error)
+
+	// Logs
+	GetContainerLogs(ctx context.Context, containerID string, lines int) ([]LogLine, error)
+	StreamContainerLogs(ctx context.Context, containerID string, lines int) (<-chan LogLine, <-chan error)
+
+	// Images
+	ListImages(ctx context.Context) ([]ImageInfo, error)
+	InspectImage(ctx context.Context, ref string) (*ImageDetail, error)
+	PullImage(ctx context.Context, refStr string) (io.ReadCloser, error)
+	PushImage(ctx context.Context, ref string) (io.ReadCloser, error)
+	TagImage(ctx context.Context, source, target string) error
+	RemoveImage(ctx context.Context, ref string, force bool) error
+	Login(ctx context.Context, creds Credentials) (Credentials, error)
+
+	// Events
+	Events(ctx context.Context) (<-chan Event, <-chan error)
+}
+
+var _ Runtime = (*Client)(nil)
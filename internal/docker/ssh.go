@@ -0,0 +1,116 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sshDialer returns a dial function that tunnels the Docker API through
+// `docker system dial-stdio` on the remote host, like the docker CLI does
+// for ssh:// hosts
+func sshDialer(u *url.URL) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		args := []string{"-o", "BatchMode=yes"}
+		if u.User != nil && u.User.Username() != "" {
+			args = append(args, "-l", u.User.Username())
+		}
+		if port := u.Port(); port != "" {
+			args = append(args, "-p", port)
+		}
+		args = append(args, "--", u.Hostname(), "docker", "system", "dial-stdio")
+
+		// Not CommandContext: the connection outlives the dial context
+		cmd := exec.Command("ssh", args...)
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		conn := &commandConn{cmd: cmd, stdin: stdin, stdout: stdout, host: u.Host}
+		cmd.Stderr = &conn.stderr
+
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("starting ssh: %w", err)
+		}
+		return conn, nil
+	}
+}
+
+// commandConn is a net.Conn over the stdin and stdout of a process
+type commandConn struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	stdout    io.ReadCloser
+	stderr    lockedBuffer
+	host      string
+	closeOnce sync.Once
+}
+
+func (c *commandConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if err == io.EOF && n == 0 {
+		if msg := strings.TrimSpace(c.stderr.String()); msg != "" {
+			return 0, fmt.Errorf("ssh %s: %s", c.host, msg)
+		}
+	}
+	return n, err
+}
+
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+func (c *commandConn) Close() error {
+	c.closeOnce.Do(func() {
+		_ = c.stdin.Close()
+		_ = c.stdout.Close()
+		if c.cmd.Process != nil {
+			_ = c.cmd.Process.Kill()
+		}
+		_ = c.cmd.Wait()
+	})
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr  { return sshAddr("local") }
+func (c *commandConn) RemoteAddr() net.Addr { return sshAddr(c.host) }
+
+// Deadlines are not supported on process pipes
+func (c *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// lockedBuffer collects the process's stderr, which is written from a
+// goroutine owned by os/exec
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+type sshAddr string
+
+func (a sshAddr) Network() string { return "ssh" }
+func (a sshAddr) String() string  { return string(a) }
@@ -0,0 +1,127 @@
+package docker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// statsBuffer is how many samples may queue up before streams block
+// waiting for the UI to catch up
+const statsBuffer = 256
+
+// StatsCollector keeps one streaming stats subscription per running
+// container and publishes every sample on a single channel
+type StatsCollector struct {
+	client  Runtime
+	samples chan ContainerInfo
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	streams map[string]*statsStream
+}
+
+type statsStream struct {
+	cancel context.CancelFunc
+}
+
+func NewStatsCollector(client Runtime) *StatsCollector {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &StatsCollector{
+		client:  client,
+		samples: make(chan ContainerInfo, statsBuffer),
+		ctx:     ctx,
+		cancel:  cancel,
+		streams: make(map[string]*statsStream),
+	}
+}
+
+// Samples returns the channel the latest stats samples are published on
+func (s *StatsCollector) Samples() <-chan ContainerInfo {
+	return s.samples
+}
+
+// Done is closed once the collector has been closed
+func (s *StatsCollector) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Sync starts a stream for every running container that doesn't have one
+// yet and stops the streams of containers no longer in the list
+func (s *StatsCollector) Sync(runningIDs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	wanted := make(map[string]bool, len(runningIDs))
+	for _, id := range runningIDs {
+		wanted[id] = true
+		if _, ok := s.streams[id]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(s.ctx)
+		stream := &statsStream{cancel: cancel}
+		s.streams[id] = stream
+		go s.run(ctx, id, stream)
+	}
+
+	for id, stream := range s.streams {
+		if !wanted[id] {
+			stream.cancel()
+			delete(s.streams, id)
+		}
+	}
+}
+
+// Close stops all streams
+func (s *StatsCollector) Close() {
+	s.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams = make(map[string]*statsStream)
+}
+
+func (s *StatsCollector) run(ctx context.Context, containerID string, stream *statsStream) {
+	defer s.forget(containerID, stream)
+
+	var prev ContainerInfo
+	samples, errs := s.client.StreamContainerStats(ctx, containerID)
+	for {
+		select {
+		case sample, ok := <-samples:
+			if !ok {
+				return
+			}
+			if sample.Sampled.IsZero() {
+				sample.Sampled = time.Now()
+			}
+			setRates(&sample, prev)
+			prev = sample
+			select {
+			case s.samples <- sample:
+			case <-ctx.Done():
+				return
+			}
+		case <-errs:
+			return
+		}
+	}
+}
+
+// forget drops a finished stream so the next Sync can restart it, unless
+// it has already been replaced by a newer one
+func (s *StatsCollector) forget(containerID string, stream *statsStream) {
+	stream.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.streams[containerID] == stream {
+		delete(s.streams, containerID)
+	}
+}
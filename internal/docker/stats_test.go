@@ -0,0 +1,29 @@
+package docker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetRates(t *testing.T) {
+	t0 := time.Now()
+	first := ContainerInfo{NetRx: 1000, NetTx: 500, Sampled: t0}
+	second := ContainerInfo{NetRx: 3000, NetTx: 500, BlockRead: 8192, BlockWrite: 4096, Sampled: t0.Add(2 * time.Second)}
+
+	setRates(&first, ContainerInfo{})
+	if first.NetRxRate != 0 {
+		t.Errorf("first sample rate = %v, want 0", first.NetRxRate)
+	}
+
+	setRates(&second, first)
+	if second.NetRxRate != 1000 || second.NetTxRate != 0 || second.BlockReadRate != 4096 || second.BlockWriteRate != 2048 {
+		t.Errorf("rates = rx %v tx %v read %v write %v", second.NetRxRate, second.NetTxRate, second.BlockReadRate, second.BlockWriteRate)
+	}
+
+	// Counters reset when the container restarts
+	restarted := ContainerInfo{NetRx: 10, Sampled: t0.Add(3 * time.Second)}
+	setRates(&restarted, second)
+	if restarted.NetRxRate != 0 {
+		t.Errorf("rate after counter reset = %v, want 0", restarted.NetRxRate)
+	}
+}
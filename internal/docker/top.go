@@ -0,0 +1,169 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProcessInfo is a process of a container as listed by ps on its host.
+// PID is the host's PID, not the one seen inside the container.
+type ProcessInfo struct {
+	PID     int
+	User    string
+	CPUPerc float64
+	MemPerc float64
+	RSS     uint64 // resident memory in bytes
+	Command string
+}
+
+// topArgs are the ps options used by ListProcesses. The daemon runs ps on
+// its host and keeps the rows whose PID belongs to the container.
+var topArgs = []string{"-eo", "pid,user,pcpu,pmem,rss,args"}
+
+// ListProcesses returns the processes running in a container
+func (c *Client) ListProcesses(ctx context.Context, containerID string) ([]ProcessInfo, error) {
+	top, err := c.cli.ContainerTop(ctx, containerID, topArgs)
+	if err != nil {
+		// Some hosts have a ps without -o (busybox); the daemon's default
+		// is plain ps -ef
+		var fallbackErr error
+		top, fallbackErr = c.cli.ContainerTop(ctx, containerID, nil)
+		if fallbackErr != nil {
+			return nil, err
+		}
+	}
+	return parseTop(top.Titles, top.Processes), nil
+}
+
+// parseTop maps ps output to processes by column title, so it reads both
+// the topArgs format and the daemon's default ps -ef
+func parseTop(titles []string, rows [][]string) []ProcessInfo {
+	col := map[string]int{}
+	for i, t := range titles {
+		switch strings.ToUpper(t) {
+		case "PID":
+			col["pid"] = i
+		case "USER", "UID":
+			col["user"] = i
+		case "%CPU", "C":
+			col["cpu"] = i
+		case "%MEM":
+			col["mem"] = i
+		case "RSS":
+			col["rss"] = i
+		case "COMMAND", "CMD", "ARGS":
+			col["cmd"] = i
+		}
+	}
+	field := func(row []string, name string) string {
+		i, ok := col[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return row[i]
+	}
+
+	procs := make([]ProcessInfo, 0, len(rows))
+	for _, row := range rows {
+		p := ProcessInfo{
+			User:    field(row, "user"),
+			Command: field(row, "cmd"),
+		}
+		p.PID, _ = strconv.Atoi(field(row, "pid"))
+		p.CPUPerc, _ = strconv.ParseFloat(field(row, "cpu"), 64)
+		p.MemPerc, _ = strconv.ParseFloat(field(row, "mem"), 64)
+		if kb, err := strconv.ParseUint(field(row, "rss"), 10, 64); err == nil {
+			p.RSS = kb * 1024
+		}
+		procs = append(procs, p)
+	}
+	return procs
+}
+
+// SignalProcess sends signal to one process of a container, given its host
+// PID as reported by ListProcesses. The API can only signal a container's
+// main process; any other process is signalled by running kill inside the
+// container, which needs the PID as the container sees it. That mapping is
+// read from the host's /proc, so it only works when the daemon runs on this
+// machine, and the image must have a kill command, which distroless and
+// scratch images lack.
+func (c *Client) SignalProcess(ctx context.Context, containerID string, pid int, signal string) error {
+	info, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	mainPID := 0
+	if info.State != nil {
+		mainPID = info.State.Pid
+	}
+	if pid == mainPID {
+		return c.cli.ContainerKill(ctx, containerID, signal)
+	}
+
+	if !c.isLocal() {
+		return fmt.Errorf("PID %d is not the main process; other processes can only be signalled on a local daemon", pid)
+	}
+	nsPID, err := namespacePID("/proc", info.ID, pid)
+	if err != nil {
+		return err
+	}
+	_, err = c.execQuiet(ctx, containerID, []string{"kill", "-s", strings.TrimPrefix(signal, "SIG"), strconv.Itoa(nsPID)})
+	if err != nil && missingCommand(err) {
+		return fmt.Errorf("kill is not available in the container; only its main process (PID %d) can be signalled", mainPID)
+	}
+	return err
+}
+
+// missingCommand reports whether an exec failed because the command isn't
+// in the container: the runtime reports that with exit code 126 or 127
+func missingCommand(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "executable file not found") ||
+		strings.HasSuffix(msg, "exit code 126") || strings.HasSuffix(msg, "exit code 127")
+}
+
+// isLocal reports whether the daemon shares this machine's process table
+func (c *Client) isLocal() bool {
+	return strings.HasPrefix(c.cli.DaemonHost(), "unix://") && fileExists("/proc/self/status")
+}
+
+// namespacePID returns the PID a host process has inside its container's
+// PID namespace. The process must belong to the container, which guards
+// against the PID having been reused or belonging to another host.
+func namespacePID(procRoot, containerID string, pid int) (int, error) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+
+	cgroup, err := os.ReadFile(filepath.Join(dir, "cgroup"))
+	if err != nil {
+		return 0, fmt.Errorf("process %d not found: %w", pid, err)
+	}
+	if !bytes.Contains(cgroup, []byte(containerID)) {
+		return 0, fmt.Errorf("process %d does not belong to the container", pid)
+	}
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return 0, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		rest, ok := strings.CutPrefix(scanner.Text(), "NSpid:")
+		if !ok {
+			continue
+		}
+		// Outermost namespace first; the container's is the last one
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			break
+		}
+		return strconv.Atoi(fields[len(fields)-1])
+	}
+	return 0, errors.New("kernel does not report namespace PIDs")
+}
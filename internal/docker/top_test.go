@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTop(t *testing.T) {
+	t.Run("topArgs", func(t *testing.T) {
+		procs := parseTop(
+			[]string{"PID", "USER", "%CPU", "%MEM", "RSS", "COMMAND"},
+			[][]string{{"4242", "root", "12.5", "1.3", "2048", "java -jar app.jar --port 8080"}},
+		)
+		want := ProcessInfo{PID: 4242, User: "root", CPUPerc: 12.5, MemPerc: 1.3, RSS: 2048 * 1024, Command: "java -jar app.jar --port 8080"}
+		if len(procs) != 1 || procs[0] != want {
+			t.Fatalf("parseTop = %+v, want %+v", procs, want)
+		}
+	})
+
+	t.Run("ps -ef", func(t *testing.T) {
+		procs := parseTop(
+			[]string{"UID", "PID", "PPID", "C", "STIME", "TTY", "TIME", "CMD"},
+			[][]string{{"999", "17", "1", "3", "10:00", "?", "00:00:01", "postgres: writer"}},
+		)
+		want := ProcessInfo{PID: 17, User: "999", CPUPerc: 3, Command: "postgres: writer"}
+		if len(procs) != 1 || procs[0] != want {
+			t.Fatalf("parseTop = %+v, want %+v", procs, want)
+		}
+	})
+}
+
+func TestMissingCommand(t *testing.T) {
+	for msg, want := range map[string]bool{
+		`OCI runtime exec failed: exec: "kill": executable file not found in $PATH`: true,
+		"kill -s HUP 7: exit code 127":                     true,
+		"kill -s HUP 7: can't kill pid 7: No such process": false,
+	} {
+		if got := missingCommand(errors.New(msg)); got != want {
+			t.Errorf("missingCommand(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestNamespacePID(t *testing.T) {
+	root := t.TempDir()
+	writeProc := func(pid, cgroup, status string) {
+		dir := filepath.Join(root, pid)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "cgroup"), []byte(cgroup), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeProc("4242", "0::/system.slice/docker-abc123.scope\n", "Name:\tjava\nNSpid:\t4242\t7\n")
+	writeProc("5000", "0::/user.slice/session-1.scope\n", "Name:\tbash\nNSpid:\t5000\n")
+
+	if got, err := namespacePID(root, "abc123", 4242); err != nil || got != 7 {
+		t.Errorf("namespacePID(4242) = %d, %v; want 7", got, err)
+	}
+	if _, err := namespacePID(root, "abc123", 5000); err == nil {
+		t.Error("a process of another cgroup should be refused")
+	}
+	if _, err := namespacePID(root, "abc123", 6000); err == nil {
+		t.Error("a missing process should be an error")
+	}
+}
@@ -55,6 +55,15 @@ var (
 			Foreground(Orange).
 			Bold(true)
 
+	// Rows marked for batch actions
+	MarkedStyle = lipgloss.NewStyle().
+			Foreground(Yellow).
+			Bold(true)
+
+	// Log stream styles
+	StderrStyle = lipgloss.NewStyle().
+			Foreground(Orange)
+
 	// Resource usage styles (for gradient display)
 	LowUsageStyle = lipgloss.NewStyle().
 			Foreground(Green).
@@ -2,13 +2,19 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types"
 	"github.com/seb07-cloud/dktop/internal/config"
 	"github.com/seb07-cloud/dktop/internal/docker"
 	"github.com/seb07-cloud/dktop/internal/theme"
@@ -22,6 +28,13 @@ const (
 	PanelImages
 	PanelContainers
 	PanelLogs
+	PanelDetail
+	PanelGraphs
+	PanelProcesses
+	PanelDiff
+	PanelFiles
+	PanelPull
+	PanelImageInfo
 )
 
 // Logo banner for the top of the app
@@ -38,6 +51,11 @@ const (
 	ModeNormal Mode = iota
 	ModeFilter
 	ModePullImage
+	ModeHostSwitch
+	ModeConfirm
+	ModePrompt
+	ModeRunForm
+	ModeLogin
 )
 
 type App struct {
@@ -50,6 +68,14 @@ type App struct {
 	imagesPanel     *ImagesPanel
 	containersPanel *ContainersPanel
 	logsPanel       *LogsPanel
+	hostsPanel      *HostsPanel
+	detailPanel     *DetailPanel
+	graphsPanel     *GraphsPanel
+	processesPanel  *ProcessesPanel
+	diffPanel       *DiffPanel
+	filesPanel      *FilesPanel
+	pullPanel       *PullPanel
+	imageInfoPanel  *DetailPanel
 	helpBar         *HelpBar
 
 	// State
@@ -57,11 +83,27 @@ type App struct {
 	mode        Mode
 	filterInput textinput.Model
 	pullInput   textinput.Model
+	confirm     *ConfirmDialog
+	prompt      *Prompt
+	runForm     *RunForm
+	loginForm   *LoginForm
 	err         error
-
-	// Docker client
-	dockerClient *docker.Client
-	config       *config.Config
+	notice      string
+
+	// Docker client. Commands capture the client when they are built, and
+	// replies fetched with it are tagged with hostGen so that ones from the
+	// previous host are dropped after a switch.
+	dockerClient   docker.Runtime
+	statsCollector *docker.StatsCollector
+	config         *config.Config
+	host           docker.Host
+	hostGen        int
+	connect        func(docker.Host) (docker.Runtime, error)
+
+	// Screen area of each panel, for mouse hit-testing, and the area below
+	// the top row that full-screen views and dialogs take over
+	hitBoxes map[Panel]rect
+	mainArea rect
 
 	// Data
 	containers  []docker.ContainerInfo
@@ -71,51 +113,357 @@ type App struct {
 	// Refresh
 	refreshInterval time.Duration
 
+	// Docker events stream; while it is up, lists are only re-fetched when
+	// an event reports a change instead of on every tick
+	eventsCancel    context.CancelFunc
+	events          <-chan docker.Event
+	eventErrs       <-chan error
+	eventsActive    bool
+	dirtyContainers map[string]bool
+	imagesDirty     bool
+	systemDirty     bool
+	refreshPending  bool
+
+	// Log follow stream for the selected container. logsGen tags stream
+	// messages so that ones from a replaced stream are dropped; logsTail
+	// is how many lines of history it starts with.
+	logsCancel      context.CancelFunc
+	logLines        <-chan docker.LogLine
+	logErrs         <-chan error
+	logsContainerID string
+	logsState       string
+	logsReattach    bool
+	logsGen         int
+	logsTail        int
+
+	// Container shown in the detail view
+	detailID string
+
+	// Image shown in the image detail view
+	imageInfoID string
+
+	// Per-container stats history and the container shown in the graphs view
+	history  *History
+	graphsID string
+
+	// Container whose processes are listed
+	processesID string
+
+	// Container whose filesystem changes are shown
+	diffID string
+
+	// Container whose files are browsed, and the copy in or out of it.
+	// transferGen tags progress messages like logsGen.
+	filesID          string
+	transferCancel   context.CancelFunc
+	transferProgress <-chan transferProgress
+	transferErr      <-chan error
+	transferGen      int
+	transferLabel    string
+	transferNotice   string
+
+	// Pull queue shown in the pull view. Running pulls report on
+	// pullUpdates until pullsCtx is cancelled; pullGen tags their messages.
+	pullsCtx    context.Context
+	pullsCancel context.CancelFunc
+	pullUpdates chan pullUpdate
+	pullWaiting bool
+	pullGen     int
+	pullWorkers int
+
 	// Cached renders
 	renderedLogo string
 }
 
 // Messages
 type tickMsg time.Time
-type containersMsg []docker.ContainerInfo
-type imagesMsg []docker.ImageInfo
-type systemStatsMsg *docker.SystemStats
+type containersMsg struct {
+	gen        int
+	containers []docker.ContainerInfo
+}
+type imagesMsg struct {
+	gen    int
+	images []docker.ImageInfo
+}
+type systemStatsMsg struct {
+	gen   int
+	stats *docker.SystemStats
+}
 type containerStatsMsg struct {
-	id    string
-	stats *docker.ContainerInfo
+	gen     int
+	samples []docker.ContainerInfo
+}
+type logLinesMsg struct {
+	gen   int
+	lines []docker.LogLine
+}
+type logsEndedMsg struct {
+	gen int
+	err error
 }
-type logsMsg string
 type errMsg error
 
-func NewApp(dockerClient *docker.Client, cfg *config.Config) *App {
+// detailMsg carries the inspect result for the detail view
+type detailMsg struct {
+	gen  int
+	id   string
+	info types.ContainerJSON
+}
+
+// imageInfoMsg carries the history and config of an image
+type imageInfoMsg struct {
+	gen    int
+	id     string
+	detail *docker.ImageDetail
+}
+
+// processesMsg carries the process list of a container, or the error that
+// ends the polling of it
+type processesMsg struct {
+	gen   int
+	id    string
+	procs []docker.ProcessInfo
+	err   error
+}
+
+// diffMsg carries the filesystem changes of a container
+type diffMsg struct {
+	gen  int
+	id   string
+	diff docker.FilesystemDiff
+}
+
+// filesMsg carries the entries of a directory in a container
+type filesMsg struct {
+	gen     int
+	id      string
+	dir     string
+	entries []docker.FileEntry
+}
+
+// transferProgressMsg and transferDoneMsg report on a copy in or out of a
+// container
+type transferProgressMsg struct {
+	gen int
+	transferProgress
+}
+type transferDoneMsg struct {
+	gen int
+	err error
+}
+
+// pullUpdate is a progress message of a queued pull or, once done is
+// set, how it ended; pullUpdatesMsg carries those that arrived together
+type pullUpdate struct {
+	id   int
+	msg  docker.ProgressMessage
+	done bool
+	err  error
+}
+type pullUpdatesMsg struct {
+	gen     int
+	updates []pullUpdate
+}
+
+// containerRunMsg reports a container created and started by the run form
+type containerRunMsg struct {
+	name string
+}
+
+// loginMsg reports credentials checked and saved by the login form
+type loginMsg struct {
+	server string
+}
+
+// imageTaggedMsg reports a tag created by the tag prompt
+type imageTaggedMsg struct {
+	source, target string
+}
+
+// processSignalledMsg reports a signal sent to a container process
+type processSignalledMsg struct {
+	id     string
+	pid    int
+	signal string
+}
+
+// containerMsg carries a single re-fetched container; info is nil if the
+// container no longer exists
+type containerMsg struct {
+	gen  int
+	id   string
+	info *docker.ContainerInfo
+}
+
+// Docker event messages
+type containerEventMsg docker.Event
+type imageEventMsg docker.Event
+type networkEventMsg docker.Event
+type volumeEventMsg docker.Event
+type eventsClosedMsg struct{ err error }
+type resubscribeMsg struct{}
+type refreshMsg struct{}
+
+// hostConnectedMsg carries a client connected to a newly selected host
+type hostConnectedMsg struct {
+	host    docker.Host
+	runtime docker.Runtime
+}
+
+const (
+	// refreshDelay coalesces bursts of events into a single re-fetch
+	refreshDelay = 100 * time.Millisecond
+	// resubscribeDelay is how long to wait before reconnecting a failed events stream
+	resubscribeDelay = 5 * time.Second
+	// maxIncrementalRefresh is the number of changed containers above which
+	// the full list is re-fetched instead of each container individually
+	maxIncrementalRefresh = 20
+)
+
+func NewApp(dockerClient docker.Runtime, host docker.Host, cfg *config.Config) *App {
 	filterInput := textinput.New()
 	filterInput.Placeholder = "Filter..."
 	filterInput.CharLimit = 50
 
 	pullInput := textinput.New()
-	pullInput.Placeholder = "image:tag"
-	pullInput.CharLimit = 100
+	pullInput.Placeholder = "image:tag ... (spaces, commas or a pasted list)"
+	pullInput.CharLimit = 4096
 
-	return &App{
-		statsPanel:      NewStatsPanel(),
+	logLines := cfg.LogLines
+	if logLines <= 0 {
+		logLines = config.DefaultConfig.LogLines
+	}
+	statsPanel := NewStatsPanel()
+	statsPanel.SetHost(host.Name)
+	historySize := cfg.HistorySize
+	if historySize <= 0 {
+		historySize = config.DefaultConfig.HistorySize
+	}
+
+	containersPanel := NewContainersPanel()
+	containersPanel.SetColumns(cfg.Columns)
+	containersPanel.SetSort(cfg.SortBy, cfg.SortDesc)
+	pullWorkers := cfg.PullWorkers
+	if pullWorkers <= 0 {
+		pullWorkers = config.DefaultConfig.PullWorkers
+	}
+
+	app := &App{
+		statsPanel:      statsPanel,
 		imagesPanel:     NewImagesPanel(),
-		containersPanel: NewContainersPanel(),
-		logsPanel:       NewLogsPanel(),
+		containersPanel: containersPanel,
+		logsPanel:       NewLogsPanel(logLines),
+		logsTail:        logLines,
+		hostsPanel:      NewHostsPanel(),
+		detailPanel:     NewDetailPanel(),
+		graphsPanel:     NewGraphsPanel(),
+		processesPanel:  NewProcessesPanel(),
+		diffPanel:       NewDiffPanel(),
+		filesPanel:      NewFilesPanel(),
+		pullPanel:       NewPullPanel(),
+		imageInfoPanel:  NewImageInfoPanel(),
 		helpBar:         NewHelpBar(),
 		activePanel:     PanelContainers,
 		mode:            ModeNormal,
 		filterInput:     filterInput,
 		pullInput:       pullInput,
 		dockerClient:    dockerClient,
+		statsCollector:  docker.NewStatsCollector(dockerClient),
 		config:          cfg,
+		host:            host,
+		connect:         connectHost,
 		refreshInterval: time.Duration(cfg.RefreshRate) * time.Millisecond,
+		dirtyContainers: make(map[string]bool),
+		history:         NewHistory(historySize),
+		pullWorkers:     pullWorkers,
 		renderedLogo:    "", // Will be set on first WindowSizeMsg
 	}
+	app.resetPulls()
+	return app
 }
 
 func (a *App) Init() tea.Cmd {
 	return tea.Batch(
 		a.tickCmd(),
+		a.subscribeEvents(),
+		a.waitForStats(),
+		a.fetchContainers(),
+		a.fetchImages(),
+		a.fetchSystemStats(),
+	)
+}
+
+// Close stops the background streams the app keeps open
+func (a *App) Close() {
+	if a.eventsCancel != nil {
+		a.eventsCancel()
+	}
+	a.statsCollector.Close()
+	a.stopLogs()
+	a.cancelTransfer()
+	a.resetPulls()
+}
+
+func connectHost(h docker.Host) (docker.Runtime, error) {
+	client, err := docker.Connect(h)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// switchHost connects to another Docker host in the background
+func (a *App) switchHost(h docker.Host) tea.Cmd {
+	connect := a.connect
+	return func() tea.Msg {
+		rt, err := connect(h)
+		if err != nil {
+			return errMsg(fmt.Errorf("connecting to %s: %w", h.Name, err))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := rt.Ping(ctx); err != nil {
+			_ = rt.Close()
+			return errMsg(fmt.Errorf("connecting to %s: %w", h.Name, err))
+		}
+
+		return hostConnectedMsg{host: h, runtime: rt}
+	}
+}
+
+// useRuntime tears down everything tied to the current client and starts
+// over against rt
+func (a *App) useRuntime(h docker.Host, rt docker.Runtime) tea.Cmd {
+	a.Close()
+	_ = a.dockerClient.Close()
+
+	a.dockerClient = rt
+	a.statsCollector = docker.NewStatsCollector(rt)
+	a.host = h
+	a.hostGen++
+
+	a.containers = nil
+	a.images = nil
+	a.systemStats = nil
+	a.dirtyContainers = make(map[string]bool)
+	a.imagesDirty = false
+	a.systemDirty = false
+	a.containersPanel.Update(nil)
+	a.imagesPanel.Update(nil)
+	a.logsContainerID = ""
+	a.logsPanel.SetContainerName("")
+	a.statsPanel.SetHost(h.Name)
+	a.history.Reset()
+	if a.inContainerView() {
+		a.activePanel = PanelContainers
+		a.updatePanelActive()
+	}
+	a.updatePanelSizes()
+
+	return tea.Batch(
+		a.subscribeEvents(),
+		a.waitForStats(),
 		a.fetchContainers(),
 		a.fetchImages(),
 		a.fetchSystemStats(),
@@ -146,11 +494,12 @@ func (a *App) tickCmd() tea.Cmd {
 }
 
 func (a *App) fetchContainers() tea.Cmd {
+	rt, gen := a.dockerClient, a.hostGen
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		containers, err := a.dockerClient.ListContainers(ctx)
+		containers, err := rt.ListContainers(ctx)
 		if err != nil {
 			return errMsg(err)
 		}
@@ -161,79 +510,332 @@ func (a *App) fetchContainers() tea.Cmd {
 				a.config.IsAutostart(containers[i].Name)
 		}
 
-		return containersMsg(containers)
+		return containersMsg{gen: gen, containers: containers}
 	}
 }
 
-func (a *App) fetchContainerStats(containerID string) tea.Cmd {
+func (a *App) fetchContainer(containerID string) tea.Cmd {
+	rt, gen := a.dockerClient, a.hostGen
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		stats, err := a.dockerClient.GetContainerStats(ctx, containerID)
+		info, err := rt.GetContainer(ctx, containerID)
 		if err != nil {
-			return nil // Silently ignore stats errors
+			return errMsg(err)
+		}
+
+		if info != nil {
+			info.Autostart = a.config.IsAutostart(info.ID) ||
+				a.config.IsAutostart(info.Name)
+		}
+
+		return containerMsg{gen: gen, id: containerID, info: info}
+	}
+}
+
+// waitForStats blocks until the stats collector publishes a sample, then
+// drains everything else already queued so a burst renders once
+func (a *App) waitForStats() tea.Cmd {
+	samples := a.statsCollector.Samples()
+	done := a.statsCollector.Done()
+	gen := a.hostGen
+	return func() tea.Msg {
+		var batch []docker.ContainerInfo
+		select {
+		case sample := <-samples:
+			batch = append(batch, sample)
+		case <-done:
+			return nil
 		}
+		for {
+			select {
+			case sample := <-samples:
+				batch = append(batch, sample)
+			default:
+				return containerStatsMsg{gen: gen, samples: batch}
+			}
+		}
+	}
+}
 
-		return containerStatsMsg{id: containerID, stats: stats}
+// syncStats points the stats collector at the currently running containers
+func (a *App) syncStats() {
+	var running []string
+	for _, c := range a.containers {
+		if c.State == "running" {
+			running = append(running, c.ID)
+		}
 	}
+	a.statsCollector.Sync(running)
 }
 
 func (a *App) fetchImages() tea.Cmd {
+	rt, gen := a.dockerClient, a.hostGen
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		images, err := a.dockerClient.ListImages(ctx)
+		images, err := rt.ListImages(ctx)
 		if err != nil {
 			return errMsg(err)
 		}
-		return imagesMsg(images)
+		return imagesMsg{gen: gen, images: images}
 	}
 }
 
 func (a *App) fetchSystemStats() tea.Cmd {
+	rt, gen := a.dockerClient, a.hostGen
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		stats, err := a.dockerClient.GetSystemStats(ctx)
+		stats, err := rt.GetSystemStats(ctx)
 		if err != nil {
 			return errMsg(err)
 		}
-		return systemStatsMsg(stats)
+		return systemStatsMsg{gen: gen, stats: stats}
 	}
 }
 
-func (a *App) fetchLogs() tea.Cmd {
+// followSelectedLogs attaches a follow stream to the selected container,
+// replacing the stream of the previous selection. A container whose stream
+// ended is re-attached once it runs again.
+func (a *App) followSelectedLogs() tea.Cmd {
 	selected := a.containersPanel.GetSelected()
 	if selected == nil {
+		if a.logsContainerID != "" {
+			a.stopLogs()
+			a.logsContainerID = ""
+			a.logsPanel.SetContainerName("")
+		}
+		return nil
+	}
+
+	switch {
+	case selected.ID != a.logsContainerID:
+		a.logsPanel.SetContainerName(selected.Name)
+		a.logsPanel.Clear()
+	case a.logsCancel != nil:
+		// Already following
+		a.logsState = selected.State
+		return nil
+	case a.logsReattach || (selected.State == "running" && a.logsState != "running"):
+		// Restarted after its stream ended; the new stream replays the tail
+		a.logsPanel.Clear()
+	default:
+		a.logsState = selected.State
 		return nil
 	}
 
-	containerID := selected.ID
-	containerName := selected.Name
-	a.logsPanel.SetContainerName(containerName)
+	a.logsContainerID = selected.ID
+	a.logsState = selected.State
+	a.logsReattach = false
+	return a.startLogStream(selected.ID)
+}
+
+func (a *App) startLogStream(containerID string) tea.Cmd {
+	a.stopLogs()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.logsCancel = cancel
+	a.logsGen++
+	a.logLines, a.logErrs = a.dockerClient.StreamContainerLogs(ctx, containerID, a.logsTail)
+
+	return a.waitForLogs()
+}
+
+func (a *App) stopLogs() {
+	if a.logsCancel != nil {
+		a.logsCancel()
+		a.logsCancel = nil
+	}
+}
 
+// waitForLogs blocks until the follow stream produces lines, then drains
+// everything already available so a burst renders once
+func (a *App) waitForLogs() tea.Cmd {
+	gen, lines, errs := a.logsGen, a.logLines, a.logErrs
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		var batch []docker.LogLine
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return logsEndedMsg{gen: gen, err: <-errs}
+			}
+			batch = append(batch, line)
+		case err := <-errs:
+			return logsEndedMsg{gen: gen, err: err}
+		}
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					// Report the end on the next wait
+					return logLinesMsg{gen: gen, lines: batch}
+				}
+				batch = append(batch, line)
+			default:
+				return logLinesMsg{gen: gen, lines: batch}
+			}
+		}
+	}
+}
 
-		logs, err := a.dockerClient.GetContainerLogs(ctx, containerID, 100)
-		if err != nil {
-			// Ignore "no such container" - container was deleted
-			if strings.Contains(err.Error(), "No such container") ||
-				strings.Contains(err.Error(), "no such container") {
-				return nil
+// subscribeEvents (re)connects to the Docker events stream, cancelling any
+// previous subscription
+func (a *App) subscribeEvents() tea.Cmd {
+	if a.eventsCancel != nil {
+		a.eventsCancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.eventsCancel = cancel
+	a.events, a.eventErrs = a.dockerClient.Events(ctx)
+	a.eventsActive = true
+
+	return a.waitForEvent()
+}
+
+// waitForEvent blocks until the next Docker event and converts it into a
+// typed message. It must be re-issued after every event to keep listening.
+func (a *App) waitForEvent() tea.Cmd {
+	events, errs := a.events, a.eventErrs
+	if events == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					return eventsClosedMsg{err: <-errs}
+				}
+				switch ev.Kind {
+				case docker.EventContainer:
+					return containerEventMsg(ev)
+				case docker.EventImage:
+					return imageEventMsg(ev)
+				case docker.EventNetwork:
+					return networkEventMsg(ev)
+				case docker.EventVolume:
+					return volumeEventMsg(ev)
+				}
+			case err := <-errs:
+				return eventsClosedMsg{err: err}
 			}
-			return errMsg(err)
 		}
-		return logsMsg(logs)
 	}
 }
 
+// scheduleRefresh queues a re-fetch of everything marked dirty
+func (a *App) scheduleRefresh() tea.Cmd {
+	if a.refreshPending {
+		return nil
+	}
+	a.refreshPending = true
+	return tea.Tick(refreshDelay, func(time.Time) tea.Msg {
+		return refreshMsg{}
+	})
+}
+
+// flushRefresh re-fetches only the containers, images and system stats
+// that events reported as changed
+func (a *App) flushRefresh() []tea.Cmd {
+	var cmds []tea.Cmd
+
+	if len(a.dirtyContainers) > maxIncrementalRefresh {
+		cmds = append(cmds, a.fetchContainers())
+	} else {
+		for id := range a.dirtyContainers {
+			cmds = append(cmds, a.fetchContainer(id))
+		}
+	}
+	if a.imagesDirty {
+		cmds = append(cmds, a.fetchImages())
+	}
+	if a.systemDirty {
+		cmds = append(cmds, a.fetchSystemStats())
+	}
+
+	a.dirtyContainers = make(map[string]bool)
+	a.imagesDirty = false
+	a.systemDirty = false
+
+	return cmds
+}
+
+// upsertContainer replaces a container in the list or prepends it if new,
+// keeping the last known stats until fresh ones arrive
+func (a *App) upsertContainer(info docker.ContainerInfo) {
+	for i := range a.containers {
+		if a.containers[i].ID == info.ID {
+			carryStats(&info, a.containers[i])
+			a.containers[i] = info
+			return
+		}
+	}
+	a.containers = append([]docker.ContainerInfo{info}, a.containers...)
+}
+
+func (a *App) removeContainer(containerID string) {
+	for i := range a.containers {
+		if a.containers[i].ID == containerID {
+			a.containers = append(a.containers[:i:i], a.containers[i+1:]...)
+			break
+		}
+	}
+	a.history.Forget(containerID)
+}
+
+// carryStats copies resource usage from a previous snapshot of the same
+// container, since list results don't include stats
+func carryStats(dst *docker.ContainerInfo, prev docker.ContainerInfo) {
+	if dst.State != "running" {
+		return
+	}
+	copyStats(dst, prev)
+}
+
+// copyStats copies the resource usage fields of a stats sample
+func copyStats(dst *docker.ContainerInfo, src docker.ContainerInfo) {
+	dst.CPUPerc = src.CPUPerc
+	dst.MemUsage = src.MemUsage
+	dst.MemLimit = src.MemLimit
+	dst.MemPerc = src.MemPerc
+	dst.NetRx = src.NetRx
+	dst.NetTx = src.NetTx
+	dst.BlockRead = src.BlockRead
+	dst.BlockWrite = src.BlockWrite
+	dst.PIDs = src.PIDs
+	dst.Sampled = src.Sampled
+	dst.NetRxRate = src.NetRxRate
+	dst.NetTxRate = src.NetTxRate
+	dst.BlockReadRate = src.BlockReadRate
+	dst.BlockWriteRate = src.BlockWriteRate
+}
+
+// updateSystemTotals calculates total CPU/Memory from running containers
+func (a *App) updateSystemTotals() {
+	if a.systemStats == nil {
+		return
+	}
+	var totalCPU float64
+	var totalMem uint64
+	for _, c := range a.containers {
+		if c.State == "running" {
+			totalCPU += c.CPUPerc
+			totalMem += c.MemUsage
+		}
+	}
+	a.systemStats.CPUUsage = totalCPU
+	a.systemStats.MemoryUsage = totalMem
+}
+
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
+	modeBefore := a.mode
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -248,74 +850,281 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 
+	case tea.MouseMsg:
+		a.handleMouse(msg)
+
 	case tickMsg:
 		cmds = append(cmds, a.tickCmd())
-		cmds = append(cmds, a.fetchContainers())
-		cmds = append(cmds, a.fetchImages())
-		cmds = append(cmds, a.fetchSystemStats())
+		if a.activePanel == PanelProcesses && !a.processesPanel.Stopped() {
+			cmds = append(cmds, a.fetchProcesses(a.processesID))
+		}
+		if a.eventsActive {
+			// Lists are kept current by the events stream; just sample the graphs
+			a.updateSystemTotals()
+			a.statsPanel.Update(a.systemStats)
+		} else {
+			cmds = append(cmds, a.fetchContainers())
+			cmds = append(cmds, a.fetchImages())
+			cmds = append(cmds, a.fetchSystemStats())
+		}
 
-		// Fetch stats for running containers
+		// Restart any stats streams that dropped while the container kept running
+		a.syncStats()
+
+	case containersMsg:
+		// Drop a list fetched from the host used before a switch
+		if msg.gen != a.hostGen {
+			break
+		}
+		list := msg.containers
+		prev := make(map[string]docker.ContainerInfo, len(a.containers))
 		for _, c := range a.containers {
-			if c.State == "running" {
-				cmds = append(cmds, a.fetchContainerStats(c.ID))
+			prev[c.ID] = c
+		}
+		for i := range list {
+			if p, ok := prev[list[i].ID]; ok {
+				carryStats(&list[i], p)
 			}
 		}
-
-		// Fetch logs for selected container
-		if a.activePanel == PanelLogs || a.activePanel == PanelContainers {
-			cmds = append(cmds, a.fetchLogs())
+		a.containers = list
+		ids := make([]string, len(list))
+		for i := range list {
+			ids[i] = list[i].ID
 		}
-
-	case containersMsg:
-		a.containers = msg
+		a.history.Retain(ids)
 		a.containersPanel.Update(a.containers)
 		a.updatePanelSizes() // Resize panels based on container count
+		a.syncStats()
+
+	case containerMsg:
+		if msg.gen != a.hostGen {
+			break
+		}
+		if msg.info == nil {
+			a.removeContainer(msg.id)
+		} else {
+			a.upsertContainer(*msg.info)
+		}
+		a.containersPanel.Update(a.containers)
+		a.updatePanelSizes()
+		a.syncStats()
+
+	case containerEventMsg:
+		if msg.Action == "destroy" {
+			// Drop it right away; there is nothing left to fetch
+			a.removeContainer(msg.ID)
+			a.containersPanel.Update(a.containers)
+			a.updatePanelSizes()
+			a.syncStats()
+		} else {
+			a.dirtyContainers[msg.ID] = true
+		}
+		if msg.ID == a.logsContainerID && (msg.Action == "start" || msg.Action == "restart") {
+			a.logsReattach = true
+		}
+		a.systemDirty = true
+		cmds = append(cmds, a.scheduleRefresh(), a.waitForEvent())
+
+	case imageEventMsg:
+		a.imagesDirty = true
+		a.systemDirty = true
+		cmds = append(cmds, a.scheduleRefresh(), a.waitForEvent())
+
+	case networkEventMsg:
+		// Connecting or disconnecting a container changes its ports
+		if id := msg.Attributes["container"]; id != "" {
+			a.dirtyContainers[id] = true
+			cmds = append(cmds, a.scheduleRefresh())
+		}
+		cmds = append(cmds, a.waitForEvent())
+
+	case volumeEventMsg:
+		// No volume view yet; keep listening
+		cmds = append(cmds, a.waitForEvent())
+
+	case refreshMsg:
+		a.refreshPending = false
+		cmds = append(cmds, a.flushRefresh()...)
+
+	case eventsClosedMsg:
+		// A cancelled stream was replaced on purpose
+		if errors.Is(msg.err, context.Canceled) {
+			break
+		}
+		// Fall back to polling until the stream can be re-established
+		a.eventsActive = false
+		cmds = append(cmds, tea.Tick(resubscribeDelay, func(time.Time) tea.Msg {
+			return resubscribeMsg{}
+		}))
+
+	case resubscribeMsg:
+		// Events may have been missed while disconnected, so resync everything
+		cmds = append(cmds,
+			a.subscribeEvents(),
+			a.fetchContainers(),
+			a.fetchImages(),
+			a.fetchSystemStats(),
+		)
 
 	case containerStatsMsg:
+		// The previous host's collector is closed; the new one has its own waiter
+		if msg.gen != a.hostGen {
+			break
+		}
 		// Update container stats
+		latest := make(map[string]docker.ContainerInfo, len(msg.samples))
+		for _, sample := range msg.samples {
+			latest[sample.ID] = sample
+			a.history.Record(sample)
+		}
 		for i := range a.containers {
-			if a.containers[i].ID == msg.id {
-				a.containers[i].CPUPerc = msg.stats.CPUPerc
-				a.containers[i].MemUsage = msg.stats.MemUsage
-				a.containers[i].MemLimit = msg.stats.MemLimit
-				a.containers[i].MemPerc = msg.stats.MemPerc
-				a.containers[i].NetRx = msg.stats.NetRx
-				a.containers[i].NetTx = msg.stats.NetTx
-				break
+			if sample, ok := latest[a.containers[i].ID]; ok {
+				copyStats(&a.containers[i], sample)
 			}
 		}
 		a.containersPanel.Update(a.containers)
+		if a.activePanel == PanelGraphs {
+			a.graphsPanel.SetSamples(a.history.Samples(a.graphsID))
+		}
+		cmds = append(cmds, a.waitForStats())
 
 	case imagesMsg:
-		a.images = msg
+		if msg.gen != a.hostGen {
+			break
+		}
+		a.images = msg.images
 		a.imagesPanel.Update(a.images)
 
 	case systemStatsMsg:
-		a.systemStats = msg
-		// Calculate total CPU/Memory from running containers
-		if a.systemStats != nil {
-			var totalCPU float64
-			var totalMem uint64
-			for _, c := range a.containers {
-				if c.State == "running" {
-					totalCPU += c.CPUPerc
-					totalMem += c.MemUsage
-				}
+		if msg.gen != a.hostGen {
+			break
+		}
+		a.systemStats = msg.stats
+		a.updateSystemTotals()
+		if a.eventsActive {
+			// Graphs are sampled on tick; don't push an extra point
+			a.statsPanel.SetStats(a.systemStats)
+		} else {
+			a.statsPanel.Update(a.systemStats)
+		}
+
+	case logLinesMsg:
+		if msg.gen != a.logsGen {
+			break
+		}
+		for _, line := range msg.lines {
+			a.logsPanel.AppendLog(line)
+		}
+		cmds = append(cmds, a.waitForLogs())
+
+	case logsEndedMsg:
+		if msg.gen != a.logsGen {
+			break
+		}
+		a.stopLogs()
+		// The stream ends normally when the container stops or is removed
+		if msg.err != nil && !errors.Is(msg.err, context.Canceled) &&
+			!strings.Contains(msg.err.Error(), "No such container") &&
+			!strings.Contains(msg.err.Error(), "no such container") {
+			a.err = msg.err
+		}
+
+	case detailMsg:
+		// Ignore a late reply after the view was closed or moved on
+		if a.activePanel == PanelDetail && msg.gen == a.hostGen && msg.id == a.detailID {
+			a.detailPanel.SetInspect(msg.info)
+		}
+
+	case imageInfoMsg:
+		if a.activePanel == PanelImageInfo && msg.gen == a.hostGen && msg.id == a.imageInfoID {
+			a.imageInfoPanel.SetImage(msg.detail, time.Now())
+		}
+
+	case processesMsg:
+		if a.activePanel == PanelProcesses && msg.gen == a.hostGen && msg.id == a.processesID {
+			if msg.err != nil {
+				a.processesPanel.Stop(msg.err)
+			} else {
+				a.processesPanel.SetProcesses(msg.procs)
 			}
-			a.systemStats.CPUUsage = totalCPU
-			a.systemStats.MemoryUsage = totalMem
 		}
-		a.statsPanel.Update(a.systemStats)
 
-	case logsMsg:
-		a.logsPanel.Update(string(msg))
+	case diffMsg:
+		if a.activePanel == PanelDiff && msg.gen == a.hostGen && msg.id == a.diffID {
+			a.diffPanel.SetDiff(msg.diff)
+		}
+
+	case filesMsg:
+		if a.activePanel == PanelFiles && msg.gen == a.hostGen && msg.id == a.filesID && msg.dir == a.filesPanel.Dir() {
+			a.filesPanel.SetEntries(msg.entries)
+		}
+
+	case transferProgressMsg:
+		if msg.gen != a.transferGen {
+			break
+		}
+		a.filesPanel.SetTransfer(a.transferLabel, msg.done, msg.total)
+		cmds = append(cmds, a.waitForTransfer())
+
+	case transferDoneMsg:
+		if msg.gen != a.transferGen {
+			break
+		}
+		if cmd := a.finishTransfer(msg.err); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case pullUpdatesMsg:
+		if msg.gen != a.pullGen {
+			break
+		}
+		a.pullWaiting = false
+		a.applyPullUpdates(msg.updates)
+		if cmd := a.schedulePulls(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case containerRunMsg:
+		a.notice = "started " + msg.name
+		a.activePanel = PanelContainers
+		a.updatePanelActive()
+
+	case loginMsg:
+		a.notice = "logged in to " + registryName(msg.server)
+
+	case imageTaggedMsg:
+		a.notice = "tagged " + msg.source + " as " + msg.target
+
+	case processSignalledMsg:
+		a.notice = fmt.Sprintf("sent %s to PID %d", msg.signal, msg.pid)
+		if a.activePanel == PanelProcesses && msg.id == a.processesID {
+			cmds = append(cmds, a.fetchProcesses(msg.id))
+		}
+
+	case hostConnectedMsg:
+		cmds = append(cmds, a.useRuntime(msg.host, msg.runtime))
+
+	case batchDoneMsg:
+		a.reportBatch(msg)
+
+	case shellExitedMsg:
+		if msg.err != nil {
+			a.err = fmt.Errorf("exec in %s: %w", msg.name, msg.err)
+		}
 
 	case errMsg:
 		a.err = msg
 	}
 
+	// Keep the logs panel attached to the selected container
+	if cmd := a.followSelectedLogs(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
 	// Update text inputs in filter/pull mode
-	if a.mode == ModeFilter {
+	if _, ok := msg.(tea.KeyMsg); ok && a.mode != modeBefore {
+		// The key that opened the input isn't typed into it
+	} else if a.mode == ModeFilter {
 		var cmd tea.Cmd
 		a.filterInput, cmd = a.filterInput.Update(msg)
 		cmds = append(cmds, cmd)
@@ -323,6 +1132,12 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		a.pullInput, cmd = a.pullInput.Update(msg)
 		cmds = append(cmds, cmd)
+	} else if a.mode == ModePrompt {
+		cmds = append(cmds, a.prompt.Update(msg))
+	} else if a.mode == ModeRunForm {
+		cmds = append(cmds, a.runForm.Update(msg))
+	} else if a.mode == ModeLogin {
+		cmds = append(cmds, a.loginForm.Update(msg))
 	}
 
 	return a, tea.Batch(cmds...)
@@ -353,11 +1168,11 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 	if a.mode == ModePullImage {
 		switch msg.String() {
 		case "enter":
-			imageName := a.pullInput.Value()
+			refs := ParsePullRefs(a.pullInput.Value())
 			a.mode = ModeNormal
 			a.pullInput.Blur()
 			a.pullInput.SetValue("")
-			return a.pullImage(imageName)
+			return a.pullImages(refs)
 		case "esc":
 			a.mode = ModeNormal
 			a.pullInput.Blur()
@@ -366,16 +1181,76 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 		return nil
 	}
 
-	// Clear error on any key press in normal mode
-	a.err = nil
+	// Handle a prompt for a value
+	if a.mode == ModePrompt {
+		done, cmd := a.prompt.HandleKey(msg.String())
+		if done {
+			a.mode = ModeNormal
+			a.prompt = nil
+		}
+		return cmd
+	}
 
-	// Normal mode
-	switch msg.String() {
-	case "q", "ctrl+c":
-		return tea.Quit
+	// Handle the run form
+	if a.mode == ModeRunForm {
+		done, cmd := a.runForm.HandleKey(msg.String())
+		if done {
+			a.mode = ModeNormal
+			a.runForm = nil
+		}
+		return cmd
+	}
 
-	case "tab":
-		a.cyclePanel()
+	// Handle the login form
+	if a.mode == ModeLogin {
+		done, cmd := a.loginForm.HandleKey(msg.String())
+		if done {
+			a.mode = ModeNormal
+			a.loginForm = nil
+		}
+		return cmd
+	}
+
+	// Handle confirmation dialog
+	if a.mode == ModeConfirm {
+		done, cmd := a.confirm.HandleKey(msg.String())
+		if done {
+			a.mode = ModeNormal
+			a.confirm = nil
+		}
+		return cmd
+	}
+
+	// Handle host switcher
+	if a.mode == ModeHostSwitch {
+		switch msg.String() {
+		case "j", "down":
+			a.hostsPanel.MoveDown()
+		case "k", "up":
+			a.hostsPanel.MoveUp()
+		case "enter":
+			a.mode = ModeNormal
+			selected := a.hostsPanel.GetSelected()
+			if selected != nil && selected.Name != a.host.Name {
+				return a.switchHost(*selected)
+			}
+		case "esc", "q":
+			a.mode = ModeNormal
+		}
+		return nil
+	}
+
+	// Clear error on any key press in normal mode
+	a.err = nil
+	a.notice = ""
+
+	// Normal mode
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+
+	case "tab":
+		a.cyclePanel()
 
 	case "j", "down":
 		a.handleDown()
@@ -391,11 +1266,102 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 	case "x":
 		if a.activePanel == PanelContainers {
 			return a.stopSelectedContainer()
+		} else if a.activePanel == PanelImages {
+			return a.untagSelectedImage()
+		} else if a.activePanel == PanelPull {
+			a.cancelSelectedPull()
+		}
+
+	case "K":
+		if a.activePanel == PanelContainers {
+			return a.killSelectedContainer()
+		} else if a.activePanel == PanelProcesses {
+			return a.signalSelectedProcess()
+		}
+
+	case "t":
+		if a.activePanel == PanelContainers {
+			return a.openProcesses()
+		} else if a.activePanel == PanelImages {
+			return a.promptTag()
+		} else if a.activePanel == PanelProcesses {
+			a.closeDetail()
+		}
+
+	case "c":
+		if a.activePanel == PanelContainers {
+			return a.openDiff()
+		} else if a.activePanel == PanelDiff {
+			a.closeDetail()
+		} else if a.activePanel == PanelPull {
+			a.pullPanel.ClearFinished()
+		}
+
+	case "f":
+		if a.activePanel == PanelContainers {
+			return a.openFiles()
+		} else if a.activePanel == PanelFiles {
+			a.closeDetail()
+		}
+
+	case "l", "right":
+		if a.activePanel == PanelImages {
+			a.imagesPanel.Expand()
+		} else if a.activePanel == PanelDiff {
+			a.diffPanel.Expand()
+		} else if a.activePanel == PanelFiles {
+			return a.openSelectedFile()
+		}
+
+	case "h", "left", "backspace":
+		if a.activePanel == PanelImages {
+			a.imagesPanel.Collapse()
+		} else if a.activePanel == PanelDiff {
+			a.diffPanel.Collapse()
+		} else if a.activePanel == PanelFiles {
+			return a.changeDir(path.Dir(a.filesPanel.Dir()))
+		}
+
+	case "u":
+		if a.activePanel == PanelFiles {
+			return a.promptUpload()
+		} else if a.activePanel == PanelImages {
+			return a.pushSelectedImage()
+		}
+
+	case "e":
+		if a.activePanel == PanelContainers {
+			return a.openShell()
 		}
 
 	case "r":
 		if a.activePanel == PanelContainers {
 			return a.restartSelectedContainer()
+		} else if a.activePanel == PanelImages {
+			return a.openRunForm()
+		} else if a.activePanel == PanelDetail {
+			return a.fetchDetail(a.detailID)
+		} else if a.activePanel == PanelImageInfo {
+			return a.fetchImageInfo(a.imageInfoID)
+		} else if a.activePanel == PanelProcesses {
+			return a.fetchProcesses(a.processesID)
+		} else if a.activePanel == PanelDiff {
+			return a.fetchDiff(a.diffID)
+		} else if a.activePanel == PanelFiles {
+			return a.fetchFiles(a.filesID, a.filesPanel.Dir())
+		} else if a.activePanel == PanelPull {
+			return a.retryPulls(a.pullPanel.GetSelected())
+		}
+
+	case "R":
+		if a.activePanel == PanelPull {
+			var failed []*PullJob
+			for _, j := range a.pullPanel.Jobs() {
+				if j.State == PullFailed {
+					failed = append(failed, j)
+				}
+			}
+			return a.retryPulls(failed...)
 		}
 
 	case "d":
@@ -403,6 +1369,8 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 			return a.deleteSelectedContainer()
 		} else if a.activePanel == PanelImages {
 			return a.deleteSelectedImage()
+		} else if a.activePanel == PanelFiles {
+			return a.promptDownload()
 		}
 
 	case "a":
@@ -411,25 +1379,119 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 		}
 
 	case "p":
-		if a.activePanel == PanelImages {
+		if a.activePanel == PanelContainers {
+			return a.togglePauseSelectedContainer()
+		} else if a.activePanel == PanelImages {
 			a.mode = ModePullImage
 			a.pullInput.Focus()
 			return textinput.Blink
+		} else if a.activePanel == PanelDetail {
+			a.detailPanel.PrevSection()
+		} else if a.activePanel == PanelImageInfo {
+			a.imageInfoPanel.PrevSection()
+		}
+
+	case "n":
+		if a.activePanel == PanelDetail {
+			a.detailPanel.NextSection()
+		} else if a.activePanel == PanelImageInfo {
+			a.imageInfoPanel.NextSection()
+		}
+
+	case "L":
+		if a.activePanel == PanelImages || a.activePanel == PanelPull {
+			return a.openLoginForm()
+		}
+
+	case "g":
+		if a.activePanel == PanelContainers {
+			a.openGraphs()
+		} else if a.activePanel == PanelGraphs {
+			a.closeDetail()
+		}
+
+	case ">", "<":
+		if a.activePanel == PanelContainers {
+			if msg.String() == ">" {
+				a.containersPanel.CycleSort(1)
+			} else {
+				a.containersPanel.CycleSort(-1)
+			}
+		}
+
+	case "I":
+		if a.activePanel == PanelContainers {
+			a.containersPanel.ToggleSortOrder()
+		}
+
+	case "P":
+		if a.activePanel == PanelImages {
+			a.activePanel = PanelPull
+			a.updatePanelActive()
+		}
+
+	case "i":
+		if a.activePanel == PanelContainers {
+			return a.openDetail()
+		} else if a.activePanel == PanelDetail {
+			a.closeDetail()
+		} else if a.activePanel == PanelImages {
+			return a.openImageInfo()
+		} else if a.activePanel == PanelImageInfo {
+			a.activePanel = PanelImages
+			a.updatePanelActive()
 		}
 
 	case "enter":
 		if a.activePanel == PanelContainers {
 			a.activePanel = PanelLogs
 			a.updatePanelActive()
-			return a.fetchLogs()
+		} else if a.activePanel == PanelImages {
+			return a.openImageInfo()
+		} else if a.activePanel == PanelDiff {
+			a.diffPanel.Toggle()
+		} else if a.activePanel == PanelFiles {
+			return a.openSelectedFile()
+		}
+
+	case " ":
+		if a.activePanel == PanelContainers {
+			a.containersPanel.ToggleMark()
+		} else if a.activePanel == PanelImages {
+			a.imagesPanel.ToggleExpand()
+		} else if a.activePanel == PanelDiff {
+			a.diffPanel.Toggle()
+		}
+
+	case "ctrl+a":
+		if a.activePanel == PanelContainers {
+			a.containersPanel.MarkAll()
+		}
+
+	case "*":
+		if a.activePanel == PanelContainers {
+			a.containersPanel.InvertMarks()
 		}
 
 	case "esc":
-		if a.activePanel == PanelLogs {
+		if a.activePanel == PanelContainers {
+			a.containersPanel.ClearMarks()
+		} else if a.activePanel == PanelLogs {
 			a.activePanel = PanelContainers
 			a.updatePanelActive()
+		} else if a.activePanel == PanelFiles && a.transferCancel != nil {
+			a.cancelTransfer()
+		} else if a.activePanel == PanelPull || a.activePanel == PanelImageInfo {
+			a.activePanel = PanelImages
+			a.updatePanelActive()
+		} else if a.inContainerView() {
+			a.closeDetail()
 		}
 
+	case "H":
+		a.hostsPanel.SetHosts(docker.AvailableHosts(a.config), a.host.Name)
+		a.mode = ModeHostSwitch
+
 	case "/":
 		a.mode = ModeFilter
 		a.filterInput.Focus()
@@ -439,6 +1501,11 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 		if a.activePanel == PanelLogs {
 			a.logsPanel.ScrollToBottom()
 		}
+
+	case "o":
+		if a.activePanel == PanelLogs {
+			a.logsPanel.CycleStream()
+		}
 	}
 
 	return nil
@@ -463,6 +1530,18 @@ func (a *App) handleDown() {
 		a.imagesPanel.MoveDown()
 	case PanelLogs:
 		a.logsPanel.ScrollDown()
+	case PanelDetail:
+		a.detailPanel.ScrollDown()
+	case PanelImageInfo:
+		a.imageInfoPanel.ScrollDown()
+	case PanelProcesses:
+		a.processesPanel.MoveDown()
+	case PanelDiff:
+		a.diffPanel.MoveDown()
+	case PanelFiles:
+		a.filesPanel.MoveDown()
+	case PanelPull:
+		a.pullPanel.MoveDown()
 	}
 }
 
@@ -474,6 +1553,18 @@ func (a *App) handleUp() {
 		a.imagesPanel.MoveUp()
 	case PanelLogs:
 		a.logsPanel.ScrollUp()
+	case PanelDetail:
+		a.detailPanel.ScrollUp()
+	case PanelImageInfo:
+		a.imageInfoPanel.ScrollUp()
+	case PanelProcesses:
+		a.processesPanel.MoveUp()
+	case PanelDiff:
+		a.diffPanel.MoveUp()
+	case PanelFiles:
+		a.filesPanel.MoveUp()
+	case PanelPull:
+		a.pullPanel.MoveUp()
 	}
 }
 
@@ -542,96 +1633,704 @@ func (a *App) updatePanelSizes() {
 	a.imagesPanel.SetSize(imagesWidth, topHeight)
 	a.containersPanel.SetSize(a.width, containerHeight)
 	a.logsPanel.SetSize(a.width, logsHeight)
+	a.hostsPanel.SetSize(a.width, containerHeight+logsHeight)
+	a.detailPanel.SetSize(a.width, containerHeight+logsHeight)
+	a.graphsPanel.SetSize(a.width, containerHeight+logsHeight)
+	a.processesPanel.SetSize(a.width, containerHeight+logsHeight)
+	a.diffPanel.SetSize(a.width, containerHeight+logsHeight)
+	a.filesPanel.SetSize(a.width, containerHeight+logsHeight)
+	a.pullPanel.SetSize(a.width, containerHeight+logsHeight)
+	a.imageInfoPanel.SetSize(a.width, containerHeight+logsHeight)
 	a.helpBar.SetWidth(a.width)
 
+	mainTop := bannerHeight + topHeight
+	a.mainArea = rect{0, mainTop, a.width, containerHeight + logsHeight}
+	a.hitBoxes = map[Panel]rect{
+		PanelStats:      {0, bannerHeight, statsWidth, topHeight},
+		PanelImages:     {statsWidth, bannerHeight, imagesWidth, topHeight},
+		PanelContainers: {0, mainTop, a.width, containerHeight},
+		PanelLogs:       {0, mainTop + containerHeight, a.width, logsHeight},
+		PanelDetail:     a.mainArea,
+		PanelGraphs:     a.mainArea,
+		PanelProcesses:  a.mainArea,
+		PanelDiff:       a.mainArea,
+		PanelFiles:      a.mainArea,
+		PanelPull:       a.mainArea,
+		PanelImageInfo:  a.mainArea,
+	}
+
 	a.updatePanelActive()
 }
 
-func (a *App) startSelectedContainer() tea.Cmd {
+// openDetail switches to the detail view of the selected container
+func (a *App) openDetail() tea.Cmd {
 	selected := a.containersPanel.GetSelected()
-	if selected == nil || selected.State == "running" {
+	if selected == nil {
 		return nil
 	}
 
-	// Copy ID to avoid race condition with tick refresh
-	containerID := selected.ID
+	if selected.ID != a.detailID {
+		a.detailPanel = NewDetailPanel()
+		a.updatePanelSizes()
+	}
+	a.detailID = selected.ID
+	a.activePanel = PanelDetail
+	a.updatePanelActive()
+	return a.fetchDetail(selected.ID)
+}
+
+// openImageInfo switches to the history and config of the selected image
+func (a *App) openImageInfo() tea.Cmd {
+	selected := a.imagesPanel.GetSelected()
+	if selected == nil {
+		return nil
+	}
 
+	if selected.ID != a.imageInfoID {
+		a.imageInfoPanel = NewImageInfoPanel()
+		a.updatePanelSizes()
+	}
+	a.imageInfoID = selected.ID
+	a.activePanel = PanelImageInfo
+	a.updatePanelActive()
+	return a.fetchImageInfo(selected.ID)
+}
+
+func (a *App) fetchImageInfo(imageID string) tea.Cmd {
+	rt, gen := a.dockerClient, a.hostGen
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		if err := a.dockerClient.StartContainer(ctx, containerID); err != nil {
+		detail, err := rt.InspectImage(ctx, imageID)
+		if err != nil {
 			return errMsg(err)
 		}
-		return nil
+		return imageInfoMsg{gen: gen, id: imageID, detail: detail}
 	}
 }
 
-func (a *App) stopSelectedContainer() tea.Cmd {
+// openGraphs switches to the history graphs of the selected container
+func (a *App) openGraphs() {
 	selected := a.containersPanel.GetSelected()
-	if selected == nil || selected.State != "running" {
+	if selected == nil {
+		return
+	}
+
+	a.graphsID = selected.ID
+	a.graphsPanel.SetContainer(selected.Name, a.history.Samples(selected.ID))
+	a.activePanel = PanelGraphs
+	a.updatePanelActive()
+}
+
+// openProcesses switches to the process list of the selected container
+func (a *App) openProcesses() tea.Cmd {
+	selected := a.containersPanel.GetSelected()
+	if selected == nil {
 		return nil
 	}
 
-	// Copy ID to avoid race condition with tick refresh
-	containerID := selected.ID
+	if selected.ID != a.processesID {
+		a.processesPanel.SetContainer(selected.Name)
+	}
+	a.processesID = selected.ID
+	a.activePanel = PanelProcesses
+	a.updatePanelActive()
+	return a.fetchProcesses(selected.ID)
+}
 
+func (a *App) fetchProcesses(containerID string) tea.Cmd {
+	rt, gen := a.dockerClient, a.hostGen
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		if err := a.dockerClient.StopContainer(ctx, containerID); err != nil {
+		procs, err := rt.ListProcesses(ctx, containerID)
+		if err != nil {
+			// Polling a container that is gone would repeat the error
+			// every tick; the panel shows it once instead
+			if containerGone(err) {
+				return processesMsg{gen: gen, id: containerID, err: err}
+			}
 			return errMsg(err)
 		}
-		return nil
+		return processesMsg{gen: gen, id: containerID, procs: procs}
 	}
 }
 
-func (a *App) restartSelectedContainer() tea.Cmd {
+// containerGone reports whether err says the container stopped or was
+// removed
+func containerGone(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "no such container") || strings.Contains(msg, "is not running")
+}
+
+// openDiff switches to the filesystem changes of the selected container
+func (a *App) openDiff() tea.Cmd {
 	selected := a.containersPanel.GetSelected()
 	if selected == nil {
 		return nil
 	}
 
-	// Copy ID to avoid race condition with tick refresh
-	containerID := selected.ID
+	if selected.ID != a.diffID {
+		a.diffPanel.SetContainer(selected.Name)
+	}
+	a.diffID = selected.ID
+	a.activePanel = PanelDiff
+	a.updatePanelActive()
+	return a.fetchDiff(selected.ID)
+}
 
+func (a *App) fetchDiff(containerID string) tea.Cmd {
+	rt, gen := a.dockerClient, a.hostGen
 	return func() tea.Msg {
+		// Sizing a large writable layer takes the daemon a while
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		if err := a.dockerClient.RestartContainer(ctx, containerID); err != nil {
+		diff, err := rt.GetContainerDiff(ctx, containerID)
+		if err != nil {
 			return errMsg(err)
 		}
-		return nil
+		return diffMsg{gen: gen, id: containerID, diff: diff}
 	}
 }
 
-func (a *App) deleteSelectedContainer() tea.Cmd {
+// openFiles switches to the file browser of the selected container
+func (a *App) openFiles() tea.Cmd {
 	selected := a.containersPanel.GetSelected()
 	if selected == nil {
 		return nil
 	}
 
-	// Copy values to avoid race condition with tick refresh
-	containerID := selected.ID
-	isRunning := selected.State == "running"
+	if selected.ID != a.filesID {
+		a.filesPanel.SetContainer(selected.Name)
+	}
+	a.filesID = selected.ID
+	a.activePanel = PanelFiles
+	a.updatePanelActive()
+	return a.fetchFiles(selected.ID, a.filesPanel.Dir())
+}
 
+func (a *App) fetchFiles(containerID, dir string) tea.Cmd {
+	rt, gen := a.dockerClient, a.hostGen
 	return func() tea.Msg {
+		// Stopped containers are listed from an archive of the directory
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		// Force remove if running
-		if err := a.dockerClient.RemoveContainer(ctx, containerID, isRunning); err != nil {
-			// Ignore "no such container" - it's already deleted
-			if strings.Contains(err.Error(), "No such container") ||
-				strings.Contains(err.Error(), "no such container") {
-				return nil
+		entries, err := rt.ListDir(ctx, containerID, dir)
+		if err != nil {
+			return errMsg(err)
+		}
+		return filesMsg{gen: gen, id: containerID, dir: dir, entries: entries}
+	}
+}
+
+// changeDir shows another directory of the browsed container
+func (a *App) changeDir(dir string) tea.Cmd {
+	if dir == a.filesPanel.Dir() {
+		return nil
+	}
+	a.filesPanel.SetDir(dir)
+	return a.fetchFiles(a.filesID, dir)
+}
+
+// openSelectedFile enters the selected directory, or offers to download
+// the selected file
+func (a *App) openSelectedFile() tea.Cmd {
+	entry := a.filesPanel.GetSelected()
+	if entry == nil {
+		return nil
+	}
+	if entry.IsDir() {
+		return a.changeDir(a.filesPanel.SelectedPath())
+	}
+	return a.promptDownload()
+}
+
+// promptDownload asks where to save the selected file or directory
+func (a *App) promptDownload() tea.Cmd {
+	entry := a.filesPanel.GetSelected()
+	if entry == nil {
+		return nil
+	}
+
+	rt, containerID, src, name := a.dockerClient, a.filesID, a.filesPanel.SelectedPath(), entry.Name
+	isDir, size := entry.IsDir(), entry.Size
+	a.prompt = NewPrompt("Download "+src+" to", "./"+name, func(dst string) tea.Cmd {
+		if dst == "" {
+			return nil
+		}
+		dst = expandHome(dst)
+		return a.startTransfer("Downloading "+name, func(ctx context.Context, report func(done, total int64)) error {
+			total := int64(-1)
+			if !isDir {
+				total = size
+				if total < 0 {
+					if stat, err := rt.StatPath(ctx, containerID, src); err == nil {
+						total = stat.Size
+					}
+				}
 			}
+			report(0, total)
+			return rt.DownloadPath(ctx, containerID, src, dst, func(n int64) { report(n, total) })
+		}, fmt.Sprintf("downloaded %s to %s", src, dst))
+	})
+	a.mode = ModePrompt
+	return textinput.Blink
+}
+
+// promptUpload asks for a local file to copy into the current directory
+func (a *App) promptUpload() tea.Cmd {
+	rt, containerID, dir := a.dockerClient, a.filesID, a.filesPanel.Dir()
+	a.prompt = NewPrompt("Upload to "+dir+" from", "", func(src string) tea.Cmd {
+		if src == "" {
+			return nil
+		}
+		src = expandHome(src)
+		info, err := os.Stat(src)
+		if err != nil {
+			return func() tea.Msg { return errMsg(err) }
+		}
+		if !info.Mode().IsRegular() {
+			return func() tea.Msg { return errMsg(fmt.Errorf("%s is not a regular file", src)) }
+		}
+
+		name := filepath.Base(src)
+		return a.startTransfer("Uploading "+name, func(ctx context.Context, report func(done, total int64)) error {
+			report(0, info.Size())
+			return rt.UploadFile(ctx, containerID, src, dir, func(n int64) { report(n, info.Size()) })
+		}, fmt.Sprintf("uploaded %s to %s", name, dir))
+	})
+	a.mode = ModePrompt
+	return textinput.Blink
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[1:])
+}
+
+// transferProgress is how far a copy has got; total is -1 if unknown
+type transferProgress struct {
+	done, total int64
+}
+
+// startTransfer runs a copy in the background. Only one runs at a time;
+// it is cancelled with Esc in the files view. notice is shown once it
+// completes.
+func (a *App) startTransfer(label string, fn func(ctx context.Context, report func(done, total int64)) error, notice string) tea.Cmd {
+	if a.transferCancel != nil {
+		return func() tea.Msg { return errMsg(errors.New("another copy is still running")) }
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := make(chan transferProgress, 1)
+	errs := make(chan error, 1)
+	a.transferCancel = cancel
+	a.transferProgress = progress
+	a.transferErr = errs
+	a.transferGen++
+	a.transferLabel = label
+	a.transferNotice = notice
+	a.filesPanel.SetTransfer(label, 0, -1)
+
+	go func() {
+		err := fn(ctx, func(done, total int64) {
+			// Keep only the latest report; the UI catches up when it can
+			select {
+			case <-progress:
+			default:
+			}
+			select {
+			case progress <- transferProgress{done: done, total: total}:
+			default:
+			}
+		})
+		// A cancelled copy fails with whatever broke first; report why
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		errs <- err
+	}()
+	return a.waitForTransfer()
+}
+
+// waitForTransfer blocks until the copy reports progress or ends
+func (a *App) waitForTransfer() tea.Cmd {
+	gen, progress, errs := a.transferGen, a.transferProgress, a.transferErr
+	return func() tea.Msg {
+		select {
+		case err := <-errs:
+			return transferDoneMsg{gen: gen, err: err}
+		case p := <-progress:
+			return transferProgressMsg{gen: gen, transferProgress: p}
+		}
+	}
+}
+
+// finishTransfer reports how a copy ended and shows an upload in the
+// listing
+func (a *App) finishTransfer(err error) tea.Cmd {
+	a.transferCancel = nil
+	a.filesPanel.ClearTransfer()
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		a.notice = "copy cancelled"
+	case err != nil:
+		a.err = err
+	default:
+		a.notice = a.transferNotice
+		if a.activePanel == PanelFiles {
+			return a.fetchFiles(a.filesID, a.filesPanel.Dir())
+		}
+	}
+	return nil
+}
+
+// cancelTransfer stops the copy in progress, if any
+func (a *App) cancelTransfer() {
+	if a.transferCancel != nil {
+		a.transferCancel()
+	}
+}
+
+// signalSelectedProcess asks which signal to send to the selected process
+func (a *App) signalSelectedProcess() tea.Cmd {
+	proc := a.processesPanel.GetSelected()
+	if proc == nil {
+		return nil
+	}
+
+	rt, containerID, pid := a.dockerClient, a.processesID, proc.PID
+	target := fmt.Sprintf("PID %d: %s", pid, truncate(proc.Command, 60))
+	d := NewConfirmDialog("Send signal to process?", target, func(d *ConfirmDialog) tea.Cmd {
+		signal := killSignals[d.Choice()]
+		return func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			if err := rt.SignalProcess(ctx, containerID, pid, signal); err != nil {
+				return errMsg(err)
+			}
+			return processSignalledMsg{id: containerID, pid: pid, signal: signal}
+		}
+	})
+	d.SetChoices(0, killSignals...)
+	d.AddNote("Except for the main process, this runs kill in the container:")
+	d.AddNote("it needs a local daemon and an image with kill (not distroless/scratch).")
+	a.confirmAction(d)
+	return nil
+}
+
+// inContainerView reports whether a full-screen view is shown in place of
+// the containers and logs panels: one of a container, or the pull queue or
+// an image from the images panel
+func (a *App) inContainerView() bool {
+	switch a.activePanel {
+	case PanelDetail, PanelGraphs, PanelProcesses, PanelDiff, PanelFiles, PanelPull, PanelImageInfo:
+		return true
+	}
+	return false
+}
+
+// closeDetail returns from a full-screen container view to the list
+func (a *App) closeDetail() {
+	a.activePanel = PanelContainers
+	a.updatePanelActive()
+}
+
+func (a *App) fetchDetail(containerID string) tea.Cmd {
+	rt, gen := a.dockerClient, a.hostGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		info, err := rt.GetContainerInspect(ctx, containerID)
+		if err != nil {
 			return errMsg(err)
 		}
+		return detailMsg{gen: gen, id: containerID, info: info}
+	}
+}
+
+// containerTargets returns the marked containers, or the selected one if
+// nothing is marked
+func (a *App) containerTargets() []docker.ContainerInfo {
+	if marked := a.containersPanel.Marked(); len(marked) > 0 {
+		return marked
+	}
+	if selected := a.containersPanel.GetSelected(); selected != nil {
+		return []docker.ContainerInfo{*selected}
+	}
+	return nil
+}
+
+// batchResult is the outcome of an action on one container
+type batchResult struct {
+	name string
+	err  error
+}
+
+// batchDoneMsg reports the outcome of an action on a set of containers
+type batchDoneMsg struct {
+	verb    string
+	results []batchResult
+}
+
+// maxBatchConcurrency bounds how many containers are acted on at once
+const maxBatchConcurrency = 8
+
+// runBatch applies fn to every target concurrently and reports each
+// outcome. Targets are copied so later list refreshes can't race, and fn
+// is handed the client of the host they were listed from.
+func (a *App) runBatch(verb string, targets []docker.ContainerInfo, timeout time.Duration, fn func(ctx context.Context, rt docker.Runtime, c docker.ContainerInfo) error) tea.Cmd {
+	if len(targets) == 0 {
+		return nil
+	}
+	targets = append([]docker.ContainerInfo(nil), targets...)
+	a.containersPanel.ClearMarks()
+	rt := a.dockerClient
+
+	return func() tea.Msg {
+		results := make([]batchResult, len(targets))
+		sem := make(chan struct{}, maxBatchConcurrency)
+		var wg sync.WaitGroup
+		for i, c := range targets {
+			wg.Add(1)
+			go func(i int, c docker.ContainerInfo) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				defer cancel()
+				results[i] = batchResult{name: c.Name, err: fn(ctx, rt, c)}
+			}(i, c)
+		}
+		wg.Wait()
+		return batchDoneMsg{verb: verb, results: results}
+	}
+}
+
+// reportBatch shows the outcome of a batch: a single failure as is, or a
+// summary naming every container that failed
+func (a *App) reportBatch(msg batchDoneMsg) {
+	var failed []string
+	var firstErr error
+	for _, r := range msg.results {
+		if r.err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", r.name, r.err))
+			if firstErr == nil {
+				firstErr = r.err
+			}
+		}
+	}
+
+	switch {
+	case len(msg.results) == 1:
+		a.err = firstErr
+	case len(failed) > 0:
+		a.err = fmt.Errorf("%s: %d ok, %d failed (%s)", msg.verb,
+			len(msg.results)-len(failed), len(failed), strings.Join(failed, "; "))
+	default:
+		a.notice = fmt.Sprintf("%s: %d containers ok", msg.verb, len(msg.results))
+	}
+}
+
+// startSelectedContainer starts the targets that are stopped. Paused
+// containers can't be started; they are resumed with unpause instead.
+func (a *App) startSelectedContainer() tea.Cmd {
+	var targets []docker.ContainerInfo
+	for _, c := range a.containerTargets() {
+		if c.State != "running" && c.State != "paused" {
+			targets = append(targets, c)
+		}
+	}
+
+	return a.runBatch("start", targets, 30*time.Second, func(ctx context.Context, rt docker.Runtime, c docker.ContainerInfo) error {
+		return rt.StartContainer(ctx, c.ID)
+	})
+}
+
+// stopSelectedContainer stops the targets that are running or paused
+func (a *App) stopSelectedContainer() tea.Cmd {
+	var targets []docker.ContainerInfo
+	for _, c := range a.containerTargets() {
+		if c.State == "running" || c.State == "paused" {
+			targets = append(targets, c)
+		}
+	}
+
+	return a.runBatch("stop", targets, a.stopBatchTimeout(targets), func(ctx context.Context, rt docker.Runtime, c docker.ContainerInfo) error {
+		return rt.StopContainer(ctx, c.ID, a.config.StopTimeoutFor(c.Name))
+	})
+}
+
+// stopBatchTimeout bounds a stop or restart of targets: the longest stop
+// timeout among them, plus time for the daemon to respond
+func (a *App) stopBatchTimeout(targets []docker.ContainerInfo) time.Duration {
+	longest := time.Duration(0)
+	for _, c := range targets {
+		longest = max(longest, a.config.StopTimeoutFor(c.Name))
+	}
+	return longest + 20*time.Second
+}
+
+// killSignals are offered by the kill dialog; SIGKILL, docker kill's
+// default, is preselected
+var killSignals = []string{"SIGTERM", "SIGINT", "SIGHUP", "SIGQUIT", "SIGUSR1", "SIGUSR2", "SIGKILL"}
+
+// killSelectedContainer asks which signal to send to the running targets
+func (a *App) killSelectedContainer() tea.Cmd {
+	var targets []docker.ContainerInfo
+	for _, c := range a.containerTargets() {
+		if c.State == "running" {
+			targets = append(targets, c)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	title := "Send signal to container?"
+	if len(targets) > 1 {
+		title = fmt.Sprintf("Send signal to %d containers?", len(targets))
+	}
+
+	d := NewConfirmDialog(title, containerNames(targets, 5), func(d *ConfirmDialog) tea.Cmd {
+		signal := killSignals[d.Choice()]
+		return a.runBatch("kill "+signal, targets, 30*time.Second, func(ctx context.Context, rt docker.Runtime, c docker.ContainerInfo) error {
+			return rt.KillContainer(ctx, c.ID, signal)
+		})
+	})
+	d.SetChoices(len(killSignals)-1, killSignals...)
+	a.confirmAction(d)
+	return nil
+}
+
+// togglePauseSelectedContainer pauses running targets and unpauses paused
+// ones. A batch with both says how many got which, and failures name the
+// action that failed.
+func (a *App) togglePauseSelectedContainer() tea.Cmd {
+	var targets []docker.ContainerInfo
+	pauses, unpauses := 0, 0
+	for _, c := range a.containerTargets() {
+		switch c.State {
+		case "running":
+			pauses++
+			targets = append(targets, c)
+		case "paused":
+			unpauses++
+			targets = append(targets, c)
+		}
+	}
+
+	mixed := pauses > 0 && unpauses > 0
+	verb := "pause"
+	switch {
+	case mixed:
+		verb = fmt.Sprintf("pause %d, unpause %d", pauses, unpauses)
+	case unpauses > 0:
+		verb = "unpause"
+	}
+
+	return a.runBatch(verb, targets, 30*time.Second, func(ctx context.Context, rt docker.Runtime, c docker.ContainerInfo) error {
+		action := "pause"
+		var err error
+		if c.State == "paused" {
+			action = "unpause"
+			err = rt.UnpauseContainer(ctx, c.ID)
+		} else {
+			err = rt.PauseContainer(ctx, c.ID)
+		}
+		if err != nil && mixed {
+			return fmt.Errorf("%s: %w", action, err)
+		}
+		return err
+	})
+}
+
+func (a *App) restartSelectedContainer() tea.Cmd {
+	targets := a.containerTargets()
+	return a.runBatch("restart", targets, a.stopBatchTimeout(targets), func(ctx context.Context, rt docker.Runtime, c docker.ContainerInfo) error {
+		return rt.RestartContainer(ctx, c.ID, a.config.StopTimeoutFor(c.Name))
+	})
+}
+
+// confirmAction shows a confirmation dialog; its action runs on confirm
+func (a *App) confirmAction(d *ConfirmDialog) {
+	a.confirm = d
+	a.mode = ModeConfirm
+}
+
+func (a *App) deleteSelectedContainer() tea.Cmd {
+	targets := a.containerTargets()
+	if len(targets) == 0 {
 		return nil
 	}
+
+	running := 0
+	for _, c := range targets {
+		if c.State == "running" {
+			running++
+		}
+	}
+
+	title := "Delete container?"
+	target := fmt.Sprintf("%s (%s, %s)", targets[0].Name, shortID(targets[0].ID), targets[0].Image)
+	if len(targets) > 1 {
+		title = fmt.Sprintf("Delete %d containers?", len(targets))
+		target = containerNames(targets, 8)
+	}
+
+	d := NewConfirmDialog(title, target, func(d *ConfirmDialog) tea.Cmd {
+		return a.deleteContainers(targets, d.Toggle("f"), d.Toggle("v"))
+	})
+	switch {
+	case running == 1 && len(targets) == 1:
+		d.AddNote("The container is running and can only be removed with force.")
+	case running > 0:
+		d.AddNote(fmt.Sprintf("%d of them are running and can only be removed with force.", running))
+	}
+	d.AddToggle("v", "Remove anonymous volumes", false)
+	d.AddToggle("f", "Force (kill if running)", running > 0)
+	a.confirmAction(d)
+	return nil
+}
+
+func (a *App) deleteContainers(targets []docker.ContainerInfo, force, removeVolumes bool) tea.Cmd {
+	return a.runBatch("delete", targets, 30*time.Second, func(ctx context.Context, rt docker.Runtime, c docker.ContainerInfo) error {
+		err := rt.RemoveContainer(ctx, c.ID, force, removeVolumes)
+		// Ignore "no such container" - it's already deleted
+		if err != nil && (strings.Contains(err.Error(), "No such container") ||
+			strings.Contains(err.Error(), "no such container")) {
+			return nil
+		}
+		return err
+	})
+}
+
+// containerNames lists up to max names, summarising the rest
+func containerNames(containers []docker.ContainerInfo, max int) string {
+	var names []string
+	for i, c := range containers {
+		if i == max {
+			names = append(names, fmt.Sprintf("and %d more", len(containers)-max))
+			break
+		}
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ", ")
 }
 
 func (a *App) deleteSelectedImage() tea.Cmd {
@@ -640,14 +2339,113 @@ func (a *App) deleteSelectedImage() tea.Cmd {
 		return nil
 	}
 
-	// Copy ID to avoid race condition with tick refresh
+	// Copy values to avoid race condition with tick refresh
 	imageID := selected.ID
+	tags := append([]string(nil), selected.Tags...)
+	tag := a.imagesPanel.SelectedTag()
+
+	target := shortID(imageID)
+	if tag != "" {
+		target = tag + " (" + target + ")"
+	}
+
+	// With several tags, the choice is between dropping the selected tag
+	// and deleting the image with all of them
+	untag := len(tags) > 1
+	d := NewConfirmDialog("Delete image?", target, func(d *ConfirmDialog) tea.Cmd {
+		ref := imageID
+		if untag && d.Choice() == 0 {
+			ref = tag
+		}
+		return a.deleteImage(ref, d.Toggle("f"))
+	})
+	if untag {
+		d.SetChoices(0,
+			"Untag "+tag+" only",
+			fmt.Sprintf("Delete image and all %d tags", len(tags)),
+		)
+	}
+	d.AddToggle("f", "Force (delete even if tagged elsewhere or used by stopped containers)", false)
+	a.confirmAction(d)
+	return nil
+}
+
+// untagSelectedImage removes the selected tag, after asking. Without it
+// the image would have no tags left, so the engine deletes it then.
+func (a *App) untagSelectedImage() tea.Cmd {
+	selected := a.imagesPanel.GetSelected()
+	tag := a.imagesPanel.SelectedTag()
+	if selected == nil || tag == "" {
+		return nil
+	}
 
+	d := NewConfirmDialog("Untag image?", tag, func(*ConfirmDialog) tea.Cmd {
+		return a.deleteImage(tag, false)
+	})
+	if len(selected.Tags) == 1 {
+		d.AddNote("This is the image's only tag, so the image is deleted with it.")
+	}
+	a.confirmAction(d)
+	return nil
+}
+
+// promptTag asks for a new reference for the selected image, starting
+// from the selected tag, like docker tag
+func (a *App) promptTag() tea.Cmd {
+	selected := a.imagesPanel.GetSelected()
+	if selected == nil {
+		return nil
+	}
+
+	source, tag := selected.ID, a.imagesPanel.SelectedTag()
+	name := shortID(source)
+	if tag != "" {
+		source, name = tag, tag
+	}
+	a.prompt = NewPrompt("Tag "+name+" as", tag, func(target string) tea.Cmd {
+		target = strings.TrimSpace(target)
+		if target == "" || target == tag {
+			return nil
+		}
+		return a.tagImage(source, target)
+	})
+	a.mode = ModePrompt
+	return textinput.Blink
+}
+
+func (a *App) tagImage(source, target string) tea.Cmd {
+	rt := a.dockerClient
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := rt.TagImage(ctx, source, target); err != nil {
+			return errMsg(fmt.Errorf("tag %s: %w", target, err))
+		}
+		return imageTaggedMsg{source: source, target: target}
+	}
+}
+
+// pushSelectedImage pushes the selected tag to its registry
+func (a *App) pushSelectedImage() tea.Cmd {
+	tag := a.imagesPanel.SelectedTag()
+	if tag == "" {
+		if a.imagesPanel.GetSelected() != nil {
+			a.notice = "tag the image before pushing it (t)"
+		}
+		return nil
+	}
+	return a.pushImage(tag)
+}
+
+// deleteImage removes an image by ID, or untags it given a tag
+func (a *App) deleteImage(ref string, force bool) tea.Cmd {
+	rt := a.dockerClient
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		if err := a.dockerClient.RemoveImage(ctx, imageID, false); err != nil {
+		if err := rt.RemoveImage(ctx, ref, force); err != nil {
 			// Ignore "no such image" - it's already deleted
 			if strings.Contains(err.Error(), "No such image") ||
 				strings.Contains(err.Error(), "no such image") {
@@ -659,69 +2457,330 @@ func (a *App) deleteSelectedImage() tea.Cmd {
 	}
 }
 
+// toggleAutostart enables autostart for the targets, or disables it if
+// they all have it already
 func (a *App) toggleAutostart() tea.Cmd {
-	selected := a.containersPanel.GetSelected()
-	if selected == nil {
+	targets := a.containerTargets()
+	if len(targets) == 0 {
 		return nil
 	}
 
-	// Copy values to avoid race condition with tick refresh
-	containerID := selected.ID
-	containerName := selected.Name
+	enable := false
+	for _, c := range targets {
+		if !a.config.IsAutostart(c.Name) {
+			enable = true
+			break
+		}
+	}
 
 	// Toggle in config
-	if a.config.IsAutostart(containerName) {
-		a.config.RemoveAutostart(containerName)
-	} else {
-		a.config.AddAutostart(containerName)
+	for _, c := range targets {
+		if enable {
+			a.config.AddAutostart(c.Name)
+		} else {
+			a.config.RemoveAutostart(c.Name)
+		}
 	}
 
 	// Save config
 	_ = a.config.Save()
 
 	// Update restart policy
+	policy := "no"
+	verb := "autostart off"
+	if enable {
+		policy = "always"
+		verb = "autostart on"
+	}
+	return a.runBatch(verb, targets, 10*time.Second, func(ctx context.Context, rt docker.Runtime, c docker.ContainerInfo) error {
+		return rt.SetRestartPolicy(ctx, c.ID, policy)
+	})
+}
+
+// openRunForm asks how to run the selected image, offering the presets
+// saved for it
+func (a *App) openRunForm() tea.Cmd {
+	selected := a.imagesPanel.GetSelected()
+	if selected == nil {
+		return nil
+	}
+
+	ref := selected.ID
+	if tag := a.imagesPanel.SelectedTag(); tag != "" {
+		ref = tag
+	}
+	presets := a.config.PresetsFor(append([]string{selected.ID}, selected.Tags...)...)
+
+	a.runForm = NewRunForm(ref, presets, a.runContainer, func(p config.RunPreset) error {
+		a.config.SavePreset(p)
+		return a.config.Save()
+	})
+	a.mode = ModeRunForm
+	return textinput.Blink
+}
+
+// runContainer creates a container and starts it, like docker run -d
+func (a *App) runContainer(opts docker.RunOptions) tea.Cmd {
+	rt := a.dockerClient
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		policy := "no"
-		if a.config.IsAutostart(containerName) {
-			policy = "always"
-		}
-
-		if err := a.dockerClient.SetRestartPolicy(ctx, containerID, policy); err != nil {
+		id, err := rt.CreateContainer(ctx, opts)
+		if err != nil {
 			return errMsg(err)
 		}
-		return nil
+		name := opts.Name
+		if name == "" {
+			name = shortID(id)
+		}
+		if err := rt.StartContainer(ctx, id); err != nil {
+			return errMsg(fmt.Errorf("created %s but could not start it: %w", name, err))
+		}
+		return containerRunMsg{name: name}
 	}
 }
 
-func (a *App) pullImage(imageName string) tea.Cmd {
-	if imageName == "" {
-		return nil
+// openLoginForm asks for registry credentials. The registry defaults to
+// that of the selected pull, or else of the selected tag.
+func (a *App) openLoginForm() tea.Cmd {
+	var ref string
+	if a.activePanel == PanelPull {
+		if job := a.pullPanel.GetSelected(); job != nil {
+			ref = job.Ref
+		}
+	} else {
+		ref = a.imagesPanel.SelectedTag()
+	}
+	server := "docker.io"
+	if registry, err := docker.RegistryOf(ref); ref != "" && err == nil {
+		server = registryName(registry)
 	}
 
+	a.loginForm = NewLoginForm(server, a.login)
+	a.mode = ModeLogin
+	return textinput.Blink
+}
+
+// login checks credentials with the registry and saves them where the
+// docker CLI looks for them, so later pulls and pushes use them
+func (a *App) login(creds docker.Credentials) tea.Cmd {
+	rt := a.dockerClient
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		reader, err := a.dockerClient.PullImage(ctx, imageName)
+		creds, err := rt.Login(ctx, creds)
 		if err != nil {
-			return errMsg(err)
+			return errMsg(fmt.Errorf("login to %s: %w", registryName(creds.Server), err))
 		}
-		defer reader.Close()
+		if err := docker.StoreCredentials(creds); err != nil {
+			return errMsg(fmt.Errorf("save credentials for %s: %w", registryName(creds.Server), err))
+		}
+		return loginMsg{server: creds.Server}
+	}
+}
 
-		// Read to completion (we could show progress but keeping it simple)
-		buf := make([]byte, 1024)
-		for {
-			_, err := reader.Read(buf)
+// pullImages queues pulls of the given refs and shows the queue. Up to
+// pull_concurrency of them run at once.
+func (a *App) pullImages(refs []string) tea.Cmd {
+	if len(refs) == 0 {
+		return nil
+	}
+	if len(a.pullPanel.Add(refs)) == 0 {
+		return func() tea.Msg {
+			return errMsg(fmt.Errorf("already pulling %s (P shows the queue)", strings.Join(refs, ", ")))
+		}
+	}
+	a.activePanel = PanelPull
+	a.updatePanelActive()
+	return a.schedulePulls()
+}
+
+// pushImage queues a push of a tag to its registry and shows the queue,
+// where it runs alongside the pulls
+func (a *App) pushImage(ref string) tea.Cmd {
+	if a.pullPanel.AddPush(ref) == nil {
+		return func() tea.Msg {
+			return errMsg(fmt.Errorf("already pushing %s (P shows the queue)", ref))
+		}
+	}
+	a.activePanel = PanelPull
+	a.updatePanelActive()
+	return a.schedulePulls()
+}
+
+// retryPulls queues failed or cancelled pulls again
+func (a *App) retryPulls(jobs ...*PullJob) tea.Cmd {
+	retried := 0
+	for _, j := range jobs {
+		if a.pullPanel.Retry(j) {
+			retried++
+		}
+	}
+	if retried == 0 {
+		return nil
+	}
+	return a.schedulePulls()
+}
+
+// schedulePulls starts queued pulls while fewer than pullWorkers run, and
+// listens for their progress
+func (a *App) schedulePulls() tea.Cmd {
+	for a.pullPanel.Count(PullActive) < a.pullWorkers {
+		job := a.pullPanel.Next()
+		if job == nil {
+			break
+		}
+		a.startPull(job)
+	}
+	if a.pullPanel.Count(PullActive) == 0 {
+		return nil
+	}
+	return a.waitForPulls()
+}
+
+// startPull runs job, a pull or a push, in the background, reporting on
+// pullUpdates
+func (a *App) startPull(job *PullJob) {
+	queue := a.pullsCtx
+	ctx, cancel := context.WithCancel(queue)
+	job.start(time.Now(), cancel)
+
+	rt, updates, id, ref := a.dockerClient, a.pullUpdates, job.ID, job.Ref
+	transfer := rt.PullImage
+	if job.Push {
+		transfer = rt.PushImage
+	}
+	go func() {
+		err := func() error {
+			reader, err := transfer(ctx, ref)
 			if err != nil {
-				break
+				return err
+			}
+			defer reader.Close()
+
+			err = docker.ReadProgress(reader, func(m docker.ProgressMessage) {
+				select {
+				case updates <- pullUpdate{id: id, msg: m}:
+				case <-ctx.Done():
+				}
+			})
+			// A cancelled pull fails reading the closed stream; report why
+			if ctx.Err() != nil {
+				return ctx.Err()
 			}
+			return err
+		}()
+		cancel()
+		select {
+		case updates <- pullUpdate{id: id, done: true, err: err}:
+		case <-queue.Done():
 		}
+	}()
+}
 
+// waitForPulls blocks until a running pull reports, then drains what is
+// already queued so a burst renders once. Only one listener runs at a time.
+func (a *App) waitForPulls() tea.Cmd {
+	if a.pullWaiting {
 		return nil
 	}
+	a.pullWaiting = true
+	gen, ctx, updates := a.pullGen, a.pullsCtx, a.pullUpdates
+	return func() tea.Msg {
+		var batch []pullUpdate
+		select {
+		case u := <-updates:
+			batch = append(batch, u)
+		case <-ctx.Done():
+			return nil
+		}
+		for {
+			select {
+			case u := <-updates:
+				batch = append(batch, u)
+			default:
+				return pullUpdatesMsg{gen: gen, updates: batch}
+			}
+		}
+	}
+}
+
+// applyPullUpdates moves the pulls along and reports those that ended
+func (a *App) applyPullUpdates(updates []pullUpdate) {
+	now := time.Now()
+	msgs := map[int][]docker.ProgressMessage{}
+	var ended []pullUpdate
+	for _, u := range updates {
+		if u.done {
+			ended = append(ended, u)
+		} else {
+			msgs[u.id] = append(msgs[u.id], u.msg)
+		}
+	}
+	for id, m := range msgs {
+		if job := a.pullPanel.Job(id); job != nil {
+			job.Apply(m, now)
+		}
+	}
+
+	for _, u := range ended {
+		job := a.pullPanel.Job(u.id)
+		if job == nil {
+			continue
+		}
+		job.Finish(u.err)
+		switch job.State {
+		case PullCancelled:
+			a.notice = job.Verb() + " of " + job.Ref + " cancelled"
+		case PullFailed:
+			a.err = fmt.Errorf("%s %s: %w%s", job.Verb(), job.Ref, u.err, loginHint(u.err))
+		default:
+			a.notice = job.Verb() + "ed " + job.Ref
+		}
+	}
+}
+
+// loginHint points at the login form when a registry turned credentials
+// down or asked for them
+func loginHint(err error) string {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"unauthorized", "denied", "authentication required", "no basic auth credentials"} {
+		if strings.Contains(msg, s) {
+			return " (L logs in to the registry)"
+		}
+	}
+	return ""
+}
+
+// cancelSelectedPull stops the selected pull or takes it off the queue
+func (a *App) cancelSelectedPull() {
+	job := a.pullPanel.GetSelected()
+	if job == nil {
+		return
+	}
+	job.Cancel()
+	if job.State == PullCancelled {
+		a.notice = job.Verb() + " of " + job.Ref + " cancelled"
+	}
+}
+
+// resetPulls cancels every pull, e.g. when leaving the host they run on,
+// and readies the queue for new ones
+func (a *App) resetPulls() {
+	if a.pullsCancel != nil {
+		a.pullsCancel()
+	}
+	for _, j := range a.pullPanel.Jobs() {
+		if j.State == PullQueued || j.State == PullActive {
+			j.Finish(context.Canceled)
+		}
+	}
+	a.pullsCtx, a.pullsCancel = context.WithCancel(context.Background())
+	a.pullUpdates = make(chan pullUpdate, 64)
+	a.pullWaiting = false
+	a.pullGen++
 }
 
 func (a *App) View() string {
@@ -732,37 +2791,64 @@ func (a *App) View() string {
 	// Top row: Stats | Images
 	topRow := lipgloss.JoinHorizontal(lipgloss.Top, a.statsPanel.View(), a.imagesPanel.View())
 
-	// Middle: Containers
-	containersView := a.containersPanel.View()
-
-	// Bottom: Logs
-	logsView := a.logsPanel.View()
+	// Middle: Containers, bottom: Logs. Other screens take over this area.
+	var mainView string
+	switch {
+	case a.mode == ModeConfirm:
+		mainView = lipgloss.Place(a.mainArea.w, a.mainArea.h, lipgloss.Center, lipgloss.Center, a.confirm.View())
+	case a.mode == ModeRunForm:
+		mainView = lipgloss.Place(a.mainArea.w, a.mainArea.h, lipgloss.Center, lipgloss.Center, a.runForm.View())
+	case a.mode == ModeLogin:
+		mainView = lipgloss.Place(a.mainArea.w, a.mainArea.h, lipgloss.Center, lipgloss.Center, a.loginForm.View())
+	case a.mode == ModeHostSwitch:
+		mainView = a.hostsPanel.View()
+	case a.activePanel == PanelDetail:
+		mainView = a.detailPanel.View()
+	case a.activePanel == PanelGraphs:
+		mainView = a.graphsPanel.View()
+	case a.activePanel == PanelProcesses:
+		mainView = a.processesPanel.View()
+	case a.activePanel == PanelDiff:
+		mainView = a.diffPanel.View()
+	case a.activePanel == PanelFiles:
+		mainView = a.filesPanel.View()
+	case a.activePanel == PanelPull:
+		mainView = a.pullPanel.View()
+	case a.activePanel == PanelImageInfo:
+		mainView = a.imageInfoPanel.View()
+	default:
+		mainView = lipgloss.JoinVertical(lipgloss.Left, a.containersPanel.View(), a.logsPanel.View())
+	}
 
 	// Help bar
 	helpView := a.helpBar.View(a.activePanel)
 
-	// Input bar (if in filter/pull mode)
+	// Input bar (if in filter/pull mode or prompting)
 	inputBar := ""
 	if a.mode == ModeFilter {
 		inputBar = theme.HighlightStyle.Render("Filter: ") + a.filterInput.View()
 	} else if a.mode == ModePullImage {
-		inputBar = theme.HighlightStyle.Render("Pull image: ") + a.pullInput.View()
+		inputBar = theme.HighlightStyle.Render("Pull images: ") + a.pullInput.View()
+	} else if a.mode == ModePrompt {
+		inputBar = a.prompt.View()
 	}
 
 	// Error display
 	errBar := ""
 	if a.err != nil {
 		errBar = theme.HighUsageStyle.Render(fmt.Sprintf("Error: %v", a.err))
+	} else if a.notice != "" {
+		errBar = theme.RunningStyle.Render(a.notice)
 	}
 
 	// Combine all using cached logo
 	var view string
 	if inputBar != "" {
-		view = lipgloss.JoinVertical(lipgloss.Left, a.renderedLogo, topRow, containersView, logsView, inputBar, helpView)
+		view = lipgloss.JoinVertical(lipgloss.Left, a.renderedLogo, topRow, mainView, inputBar, helpView)
 	} else if errBar != "" {
-		view = lipgloss.JoinVertical(lipgloss.Left, a.renderedLogo, topRow, containersView, logsView, errBar, helpView)
+		view = lipgloss.JoinVertical(lipgloss.Left, a.renderedLogo, topRow, mainView, errBar, helpView)
 	} else {
-		view = lipgloss.JoinVertical(lipgloss.Left, a.renderedLogo, topRow, containersView, logsView, helpView)
+		view = lipgloss.JoinVertical(lipgloss.Left, a.renderedLogo, topRow, mainView, helpView)
 	}
 
 	return view
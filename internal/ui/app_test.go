@@ -0,0 +1,1297 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/seb07-cloud/dktop/internal/config"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/docker/dockertest"
+)
+
+// quietPeriod is how long the harness waits for further messages before
+// considering the app settled. It must exceed refreshDelay.
+const quietPeriod = 300 * time.Millisecond
+
+// harness drives an App the way the Bubble Tea runtime does: commands run
+// in their own goroutines and their messages are fed back into Update on
+// the test goroutine.
+type harness struct {
+	t    *testing.T
+	app  *App
+	fake *dockertest.Runtime
+	msgs chan tea.Msg
+}
+
+func newHarness(t *testing.T, fake *dockertest.Runtime) *harness {
+	t.Helper()
+
+	// Keep config saves out of the real home directory
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+
+	cfg := config.DefaultConfig
+	cfg.AutostartList = nil
+	cfg.RefreshRate = int(time.Hour / time.Millisecond) // only events drive refreshes
+
+	h := &harness{
+		t:    t,
+		app:  NewApp(fake, docker.Host{Name: "default"}, &cfg),
+		fake: fake,
+		msgs: make(chan tea.Msg, 256),
+	}
+	t.Cleanup(h.app.Close)
+
+	h.send(tea.WindowSizeMsg{Width: 160, Height: 60})
+	h.exec(h.app.Init())
+	h.settle()
+	return h
+}
+
+func (h *harness) exec(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	go func() {
+		switch msg := cmd().(type) {
+		case nil:
+		case tea.BatchMsg:
+			for _, c := range msg {
+				h.exec(c)
+			}
+		default:
+			h.msgs <- msg
+		}
+	}()
+}
+
+func (h *harness) send(msg tea.Msg) {
+	_, cmd := h.app.Update(msg)
+	h.exec(cmd)
+}
+
+func (h *harness) key(k string) {
+	h.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+}
+
+// settle processes messages until the app stops producing them
+func (h *harness) settle() {
+	for {
+		select {
+		case msg := <-h.msgs:
+			h.send(msg)
+		case <-time.After(quietPeriod):
+			return
+		}
+	}
+}
+
+func (h *harness) container(id string) *docker.ContainerInfo {
+	for i := range h.app.containers {
+		if h.app.containers[i].ID == id {
+			return &h.app.containers[i]
+		}
+	}
+	return nil
+}
+
+func seeded() *dockertest.Runtime {
+	fake := dockertest.New()
+	fake.AddContainer(docker.ContainerInfo{ID: "web1", Name: "web", Image: "nginx", State: "running", Status: "Up 2 hours"})
+	fake.AddContainer(docker.ContainerInfo{ID: "db1", Name: "db", Image: "postgres", State: "exited", Status: "Exited (0)"})
+	fake.AddImage(docker.ImageInfo{ID: "sha256:nginx", Tags: []string{"nginx:latest"}})
+	return fake
+}
+
+func TestInitialLoad(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	if got := len(h.app.containers); got != 2 {
+		t.Fatalf("containers = %d, want 2", got)
+	}
+	if got := len(h.app.images); got != 1 {
+		t.Fatalf("images = %d, want 1", got)
+	}
+	if h.app.systemStats == nil || h.app.systemStats.ContainersRunning != 1 {
+		t.Fatalf("system stats not loaded: %+v", h.app.systemStats)
+	}
+	if !h.app.eventsActive {
+		t.Fatal("events stream should be active")
+	}
+}
+
+func TestContainerEventRefetchesOnlyThatContainer(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.fake.AddContainer(docker.ContainerInfo{ID: "cache1", Name: "cache", Image: "redis", State: "running"})
+	h.fake.Emit(docker.Event{Kind: docker.EventContainer, Action: "create", ID: "cache1"})
+	h.settle()
+
+	if h.container("cache1") == nil {
+		t.Fatal("created container not added")
+	}
+	if got := len(h.fake.Calls("ListContainers")); got != 1 {
+		t.Errorf("ListContainers called %d times, want 1 (initial load only)", got)
+	}
+	if got := len(h.fake.Calls("GetContainer")); got != 1 {
+		t.Errorf("GetContainer called %d times, want 1", got)
+	}
+}
+
+func TestDestroyEventRemovesContainer(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.fake.Emit(docker.Event{Kind: docker.EventContainer, Action: "destroy", ID: "db1"})
+	h.settle()
+
+	if h.container("db1") != nil {
+		t.Fatal("destroyed container still listed")
+	}
+}
+
+func TestEventsFailureFallsBackToPolling(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.fake.FailEvents(errors.New("connection reset"))
+	h.settle()
+
+	if h.app.eventsActive {
+		t.Fatal("events should be inactive after the stream fails")
+	}
+
+	listed := len(h.fake.Calls("ListContainers"))
+	h.send(tickMsg(time.Now()))
+	h.settle()
+
+	if got := len(h.fake.Calls("ListContainers")); got != listed+1 {
+		t.Errorf("tick without events should poll: ListContainers %d -> %d", listed, got)
+	}
+}
+
+func TestStatsSamplesUpdateContainers(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	if !h.fake.SendStats(docker.ContainerInfo{ID: "web1", CPUPerc: 42, MemUsage: 1024}) {
+		t.Fatal("no stats stream open for running container")
+	}
+	h.settle()
+
+	c := h.container("web1")
+	if c == nil || c.CPUPerc != 42 || c.MemUsage != 1024 {
+		t.Fatalf("stats not applied: %+v", c)
+	}
+}
+
+func TestStartSelectedContainer(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.key("j") // select db
+	h.key("s")
+	h.settle()
+
+	calls := h.fake.Calls("StartContainer")
+	if len(calls) != 1 || calls[0].Args[0] != "db1" {
+		t.Fatalf("StartContainer calls = %+v, want one for db1", calls)
+	}
+}
+
+func TestStartIgnoresRunningContainer(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.key("s") // web is already running
+	h.settle()
+
+	if calls := h.fake.Calls("StartContainer"); len(calls) != 0 {
+		t.Fatalf("StartContainer called on running container: %+v", calls)
+	}
+}
+
+func TestActionErrorIsShown(t *testing.T) {
+	h := newHarness(t, seeded())
+	h.fake.FailOn("StopContainer", errors.New("permission denied"))
+
+	h.key("x")
+	h.settle()
+
+	if h.app.err == nil || h.app.err.Error() != "permission denied" {
+		t.Fatalf("err = %v, want permission denied", h.app.err)
+	}
+}
+
+func TestToggleAutostartSetsRestartPolicy(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.key("a")
+	h.settle()
+
+	if got := h.fake.RestartPolicy("web1"); got != "always" {
+		t.Fatalf("restart policy = %q, want always", got)
+	}
+	if !h.app.config.IsAutostart("web") {
+		t.Fatal("container not added to autostart list")
+	}
+}
+
+func TestSwitchHostReconnects(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	remote := dockertest.New()
+	remote.AddContainer(docker.ContainerInfo{ID: "ci1", Name: "runner", Image: "gitlab-runner", State: "running"})
+
+	var connectedTo docker.Host
+	h.app.connect = func(host docker.Host) (docker.Runtime, error) {
+		connectedTo = host
+		return remote, nil
+	}
+	h.app.config.Hosts = []config.HostConfig{{Name: "build", Host: "tcp://build:2376"}}
+
+	h.key("H")
+	h.key("j") // default -> build
+	h.send(tea.KeyMsg{Type: tea.KeyEnter})
+	h.settle()
+
+	if connectedTo.Name != "build" || connectedTo.Host != "tcp://build:2376" {
+		t.Fatalf("connected to %+v, want build", connectedTo)
+	}
+	if h.app.host.Name != "build" || h.app.statsPanel.host != "build" {
+		t.Fatalf("active host = %q, stats header = %q", h.app.host.Name, h.app.statsPanel.host)
+	}
+	if len(h.app.containers) != 1 || h.container("ci1") == nil {
+		t.Fatalf("containers not reloaded from new host: %+v", h.app.containers)
+	}
+	if len(h.fake.Calls("Close")) != 1 {
+		t.Error("previous client not closed")
+	}
+}
+
+func TestSwitchHostDropsRepliesFromPreviousHost(t *testing.T) {
+	h := newHarness(t, seeded())
+	// Fetches still in flight when the switch happens
+	listContainers, listImages := h.app.fetchContainers(), h.app.fetchImages()
+
+	remote := dockertest.New()
+	remote.AddContainer(docker.ContainerInfo{ID: "ci1", Name: "runner", Image: "gitlab-runner", State: "running"})
+	h.send(hostConnectedMsg{host: docker.Host{Name: "build"}, runtime: remote})
+	h.settle()
+
+	calls := len(h.fake.Calls("ListContainers"))
+	h.send(listContainers())
+	h.send(listImages())
+	h.settle()
+
+	if got := len(h.fake.Calls("ListContainers")); got != calls+1 {
+		t.Errorf("previous host listed %d times, want the pending fetch to use it", got-calls)
+	}
+	if len(h.app.containers) != 1 || h.container("ci1") == nil {
+		t.Errorf("containers = %+v, want only the new host's", h.app.containers)
+	}
+	if len(h.app.images) != 0 {
+		t.Errorf("images = %+v, want the new host's (none)", h.app.images)
+	}
+}
+
+func TestLogsKeepStreamTags(t *testing.T) {
+	fake := seeded()
+	fake.SetLogs("web1",
+		docker.LogLine{Stream: docker.Stdout, Text: "GET / 200"},
+		docker.LogLine{Stream: docker.Stderr, Text: "upstream timed out"},
+		docker.LogLine{Stream: docker.Stdout, Text: "GET /health 200"},
+	)
+	h := newHarness(t, fake)
+
+	h.send(tea.KeyMsg{Type: tea.KeyEnter}) // open logs for web
+	h.settle()
+
+	if got := len(h.app.logsPanel.visible()); got != 3 {
+		t.Fatalf("visible lines = %d, want 3", got)
+	}
+
+	h.key("o") // stdout only
+	if got := len(h.app.logsPanel.visible()); got != 2 {
+		t.Errorf("stdout lines = %d, want 2", got)
+	}
+
+	h.key("o") // stderr only
+	lines := h.app.logsPanel.visible()
+	if len(lines) != 1 || lines[0].Text != "upstream timed out" {
+		t.Errorf("stderr lines = %+v", lines)
+	}
+}
+
+func TestLogStreamUsesDefaultTailWhenUnset(t *testing.T) {
+	cfg := config.DefaultConfig
+	cfg.LogLines = 0
+	fake := seeded()
+	app := NewApp(fake, docker.Host{Name: "default"}, &cfg)
+	defer app.Close()
+
+	app.startLogStream("web1")
+	calls := fake.Calls("StreamContainerLogs")
+	if len(calls) != 1 || calls[0].Args[1] != config.DefaultConfig.LogLines {
+		t.Errorf("StreamContainerLogs calls = %+v, want the default tail", calls)
+	}
+}
+
+func (h *harness) streamedLogs() []string {
+	var ids []string
+	for _, c := range h.fake.Calls("StreamContainerLogs") {
+		ids = append(ids, c.Args[0].(string))
+	}
+	return ids
+}
+
+func TestLogsFollowSelectedContainer(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	calls := h.fake.Calls("StreamContainerLogs")
+	if len(calls) != 1 || calls[0].Args[0] != "web1" || calls[0].Args[1] != h.app.config.LogLines {
+		t.Fatalf("StreamContainerLogs calls = %+v, want web1 with log_lines tail", calls)
+	}
+
+	h.fake.AppendLogs("web1", docker.LogLine{Stream: docker.Stdout, Text: "new request"})
+	h.settle()
+
+	lines := h.app.logsPanel.visible()
+	if len(lines) != 1 || lines[0].Text != "new request" {
+		t.Fatalf("followed lines = %+v", lines)
+	}
+}
+
+func TestLogsStreamReplacedOnSelectionChange(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.key("j") // select db
+	h.settle()
+
+	if got := h.streamedLogs(); len(got) != 2 || got[1] != "db1" {
+		t.Fatalf("streams = %v, want [web1 db1]", got)
+	}
+
+	// Lines for the old selection must not leak into the panel
+	h.fake.AppendLogs("web1", docker.LogLine{Stream: docker.Stdout, Text: "stale"})
+	h.fake.AppendLogs("db1", docker.LogLine{Stream: docker.Stdout, Text: "ready"})
+	h.settle()
+
+	lines := h.app.logsPanel.visible()
+	if len(lines) != 1 || lines[0].Text != "ready" {
+		t.Fatalf("lines = %+v, want only db output", lines)
+	}
+}
+
+func TestLogsReattachAfterRestart(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.fake.EndLogs("web1") // container died
+	h.settle()
+	if h.app.logsCancel != nil {
+		t.Fatal("stream should be closed after the container stopped")
+	}
+
+	h.fake.Emit(docker.Event{Kind: docker.EventContainer, Action: "start", ID: "web1"})
+	h.settle()
+
+	if got := h.streamedLogs(); len(got) != 2 || got[1] != "web1" {
+		t.Fatalf("streams = %v, want web1 re-attached", got)
+	}
+}
+
+func TestDetailViewShowsInspect(t *testing.T) {
+	fake := seeded()
+	fake.SetInspect("web1", types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    "web1",
+			Name:  "/web",
+			State: &types.ContainerState{Status: "running", Running: true},
+		},
+		Config: &container.Config{Env: []string{"DB_PASSWORD=hunter2"}},
+	})
+	h := newHarness(t, fake)
+
+	h.key("i")
+	h.settle()
+
+	if h.app.activePanel != PanelDetail {
+		t.Fatalf("active panel = %v, want detail", h.app.activePanel)
+	}
+	if calls := h.fake.Calls("GetContainerInspect"); len(calls) == 0 || calls[len(calls)-1].Args[0] != "web1" {
+		t.Fatalf("GetContainerInspect calls = %+v", calls)
+	}
+	view := h.app.detailPanel.View()
+	if !strings.Contains(view, "DB_PASSWORD=********") || strings.Contains(view, "hunter2") {
+		t.Errorf("env not masked in view:\n%s", view)
+	}
+
+	h.send(tea.KeyMsg{Type: tea.KeyEsc})
+	if h.app.activePanel != PanelContainers {
+		t.Fatalf("esc should return to containers, got %v", h.app.activePanel)
+	}
+}
+
+func TestGraphsShowSelectedContainerHistory(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	t0 := time.Now()
+	for i := 0; i < 3; i++ {
+		h.fake.SendStats(docker.ContainerInfo{ID: "web1", CPUPerc: float64(10 * (i + 1)), NetRx: uint64(1024 * i), Sampled: t0.Add(time.Duration(i) * time.Second)})
+		h.settle()
+	}
+
+	h.key("g")
+	if h.app.activePanel != PanelGraphs {
+		t.Fatalf("active panel = %v, want graphs", h.app.activePanel)
+	}
+	samples := h.app.graphsPanel.samples
+	if len(samples) != 3 || samples[2].CPUPerc != 30 || samples[2].NetRxRate != 1024 {
+		t.Fatalf("graph samples = %+v", samples)
+	}
+	if view := h.app.graphsPanel.View(); !strings.Contains(view, "1.0KB/s") {
+		t.Errorf("network rate not shown:\n%s", view)
+	}
+
+	h.fake.Emit(docker.Event{Kind: docker.EventContainer, Action: "destroy", ID: "web1"})
+	h.settle()
+	if h.app.history.Samples("web1") != nil {
+		t.Error("history kept for destroyed container")
+	}
+}
+
+func TestRateColumns(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	t0 := time.Now()
+	h.fake.SendStats(docker.ContainerInfo{ID: "web1", NetRx: 0, BlockRead: 0, PIDs: 7, Sampled: t0})
+	h.fake.SendStats(docker.ContainerInfo{ID: "web1", NetRx: 2048, BlockRead: 4 << 20, PIDs: 7, Sampled: t0.Add(time.Second)})
+	h.settle()
+
+	c := h.container("web1")
+	if c == nil || c.NetRxRate != 2048 || c.BlockReadRate != 4<<20 || c.PIDs != 7 {
+		t.Fatalf("rates not applied: %+v", c)
+	}
+
+	view := h.app.containersPanel.View()
+	for _, want := range []string{"PIDS", "NET RX/TX", "IO R/W", "2K/0B", "4M/0B"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("containers view missing %q", want)
+		}
+	}
+
+	h.app.containersPanel.SetColumns(nil)
+	if view := h.app.containersPanel.View(); strings.Contains(view, "NET RX/TX") || !strings.Contains(view, "PIDS") {
+		t.Error("optional columns should hide, PIDS should stay")
+	}
+}
+
+func TestSortKeepsCursorOnContainer(t *testing.T) {
+	fake := seeded()
+	fake.AddContainer(docker.ContainerInfo{ID: "cache1", Name: "cache", Image: "redis", State: "running"})
+	h := newHarness(t, fake)
+
+	// state sort: cache, web (running, by name), then db
+	h.key("j") // select web
+	h.key(">") // sort by cpu
+	h.key("I") // descending
+	if h.app.containersPanel.sortBy != SortCPU || !h.app.containersPanel.sortDesc {
+		t.Fatalf("sort = %s desc=%v, want cpu desc", h.app.containersPanel.sortBy, h.app.containersPanel.sortDesc)
+	}
+
+	h.fake.SendStats(docker.ContainerInfo{ID: "cache1", CPUPerc: 80})
+	h.fake.SendStats(docker.ContainerInfo{ID: "web1", CPUPerc: 5})
+	h.settle()
+
+	var order []string
+	for _, c := range h.app.containersPanel.GetFiltered() {
+		order = append(order, c.Name)
+	}
+	if strings.Join(order, ",") != "cache,web,db" {
+		t.Fatalf("order = %v, want cache,web,db", order)
+	}
+	if sel := h.app.containersPanel.GetSelected(); sel == nil || sel.ID != "web1" {
+		t.Fatalf("selected = %+v, want web to stay selected", sel)
+	}
+
+	h.fake.SendStats(docker.ContainerInfo{ID: "web1", CPUPerc: 95})
+	h.settle()
+	if h.app.containersPanel.selected != 0 || h.app.containersPanel.GetSelected().ID != "web1" {
+		t.Fatalf("cursor should follow web to the top, at row %d", h.app.containersPanel.selected)
+	}
+	if !strings.Contains(h.app.containersPanel.View(), "CPU▼") {
+		t.Error("sort indicator missing from header")
+	}
+}
+
+// cellOf finds the screen position of text in the rendered view
+func (h *harness) cellOf(text string) (x, y int) {
+	h.t.Helper()
+	for y, line := range strings.Split(ansi.Strip(h.app.View()), "\n") {
+		if i := strings.Index(line, text); i >= 0 {
+			return len([]rune(line[:i])), y
+		}
+	}
+	h.t.Fatalf("%q not on screen", text)
+	return 0, 0
+}
+
+func (h *harness) click(x, y int) {
+	h.send(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+}
+
+func TestMouseSelectsAndSorts(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.click(h.cellOf("postgres"))
+	if sel := h.app.containersPanel.GetSelected(); sel == nil || sel.ID != "db1" {
+		t.Fatalf("selected = %+v, want db after clicking its row", sel)
+	}
+
+	h.click(h.cellOf("IMAGE"))
+	if h.app.containersPanel.sortBy != SortImage || h.app.containersPanel.sortDesc {
+		t.Fatalf("sort = %s desc=%v, want image asc", h.app.containersPanel.sortBy, h.app.containersPanel.sortDesc)
+	}
+	h.click(h.cellOf("IMAGE▲"))
+	if !h.app.containersPanel.sortDesc {
+		t.Fatal("clicking the sort column again should reverse it")
+	}
+
+	h.click(h.cellOf("REPOSITORY:TAG"))
+	if h.app.activePanel != PanelImages {
+		t.Fatalf("active panel = %v, want images after click", h.app.activePanel)
+	}
+}
+
+func TestMouseWheelScrollsLogs(t *testing.T) {
+	fake := seeded()
+	var lines []docker.LogLine
+	for i := 0; i < 100; i++ {
+		lines = append(lines, docker.LogLine{Stream: docker.Stdout, Text: fmt.Sprintf("line %d", i)})
+	}
+	fake.SetLogs("web1", lines...)
+	h := newHarness(t, fake)
+
+	x, y := h.cellOf("line 99")
+	before := h.app.logsPanel.offset
+	h.send(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	if got := h.app.logsPanel.offset; got != before-wheelLines {
+		t.Fatalf("logs offset = %d, want %d", got, before-wheelLines)
+	}
+	if h.app.activePanel != PanelContainers {
+		t.Error("wheel should not move focus")
+	}
+}
+
+func TestDeleteContainerAsksFirst(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.key("d") // web is running
+	if h.app.mode != ModeConfirm {
+		t.Fatal("delete should open a confirmation dialog")
+	}
+	if view := h.app.View(); !strings.Contains(view, "web") || !strings.Contains(view, "[x] Force") {
+		t.Errorf("dialog should name the target and pre-select force:\n%s", view)
+	}
+	h.key("n")
+	h.settle()
+	if calls := h.fake.Calls("RemoveContainer"); len(calls) != 0 {
+		t.Fatalf("cancelled delete still removed: %+v", calls)
+	}
+
+	h.key("d")
+	h.key("v") // also remove anonymous volumes
+	h.send(tea.KeyMsg{Type: tea.KeyEnter})
+	h.settle()
+
+	calls := h.fake.Calls("RemoveContainer")
+	if len(calls) != 1 || calls[0].Args[0] != "web1" || calls[0].Args[1] != true || calls[0].Args[2] != true {
+		t.Fatalf("RemoveContainer calls = %+v, want web1 forced with volumes", calls)
+	}
+	if h.app.mode != ModeNormal {
+		t.Error("dialog should close after confirming")
+	}
+}
+
+func TestDeleteImageUntagOrDelete(t *testing.T) {
+	fake := seeded()
+	fake.AddImage(docker.ImageInfo{ID: "sha256:app", Tags: []string{"app:1.0", "app:latest"}, Size: 1})
+	h := newHarness(t, fake)
+
+	h.send(tea.KeyMsg{Type: tea.KeyTab}) // images panel
+	var appRow int
+	for i, img := range h.app.imagesPanel.GetFiltered() {
+		if img.ID == "sha256:app" {
+			appRow = i
+		}
+	}
+	h.app.imagesPanel.SelectRow(appRow)
+
+	h.key("d")
+	h.key("y") // default: untag only
+	h.settle()
+	calls := h.fake.Calls("RemoveImage")
+	if len(calls) != 1 || calls[0].Args[0] != "app:1.0" {
+		t.Fatalf("RemoveImage calls = %+v, want untag of app:1.0", calls)
+	}
+
+	// The fake emits no image events, so the panel still lists both tags
+	h.key("d")
+	h.send(tea.KeyMsg{Type: tea.KeyTab}) // delete the image
+	h.key("f")
+	h.key("y")
+	h.settle()
+	calls = h.fake.Calls("RemoveImage")
+	if len(calls) != 2 || calls[1].Args[0] != "sha256:app" || calls[1].Args[1] != true {
+		t.Fatalf("RemoveImage calls = %+v, want forced delete by ID", calls)
+	}
+}
+
+func TestTagUntagAndPushImage(t *testing.T) {
+	fake := seeded()
+	fake.SetPushOutput("localhost:5000/web:1", strings.Join([]string{
+		`{"status":"The push refers to repository [localhost:5000/web]"}`,
+		`{"status":"Pushing","progressDetail":{"current":256,"total":1024},"id":"layer1"}`,
+	}, "\n"), true)
+	h := newHarness(t, fake)
+	imageChanged := func() {
+		h.fake.Emit(docker.Event{Kind: docker.EventImage, Action: "tag", ID: "sha256:nginx"})
+		h.settle()
+	}
+
+	h.send(tea.KeyMsg{Type: tea.KeyTab}) // images panel, nginx:latest selected
+	h.key("t")
+	if h.app.mode != ModePrompt || h.app.prompt.input.Value() != "nginx:latest" {
+		t.Fatalf("tag prompt not prefilled: mode %v, %q", h.app.mode, h.app.prompt.input.Value())
+	}
+	h.app.prompt.input.SetValue("localhost:5000/web:1")
+	h.send(tea.KeyMsg{Type: tea.KeyEnter})
+	h.settle()
+	calls := h.fake.Calls("TagImage")
+	if len(calls) != 1 || calls[0].Args[0] != "nginx:latest" || calls[0].Args[1] != "localhost:5000/web:1" {
+		t.Fatalf("TagImage calls = %+v", calls)
+	}
+	imageChanged()
+
+	// The new tag folds under the first until the image is expanded
+	view := ansi.Strip(h.app.imagesPanel.View())
+	if !strings.Contains(view, "nginx:latest (+1)") || strings.Contains(view, "localhost:5000/web:1") {
+		t.Fatalf("folded tags not shown:\n%s", view)
+	}
+	h.key(" ")
+	h.key("j")
+	if view := ansi.Strip(h.app.imagesPanel.View()); !strings.Contains(view, "└ localhost:5000/web:1") {
+		t.Fatalf("expanded tags not shown:\n%s", view)
+	}
+	if tag := h.app.imagesPanel.SelectedTag(); tag != "localhost:5000/web:1" {
+		t.Fatalf("selected tag = %q", tag)
+	}
+
+	// Pushes stream their progress into the pull queue
+	h.key("u")
+	h.settle()
+	view = ansi.Strip(h.app.View())
+	if h.app.activePanel != PanelPull || !strings.Contains(view, "pushing") || !strings.Contains(view, "layer1") {
+		t.Fatalf("push progress not shown:\n%s", view)
+	}
+	h.key("x")
+	h.settle()
+	if h.app.notice != "push of localhost:5000/web:1 cancelled" {
+		t.Errorf("notice = %q", h.app.notice)
+	}
+
+	// Untag removes only the selected tag
+	h.send(tea.KeyMsg{Type: tea.KeyEsc})
+	h.key("x")
+	h.key("y")
+	h.settle()
+	calls = h.fake.Calls("RemoveImage")
+	if len(calls) != 1 || calls[0].Args[0] != "localhost:5000/web:1" || calls[0].Args[1] != false {
+		t.Fatalf("RemoveImage calls = %+v, want untag of localhost:5000/web:1", calls)
+	}
+	imageChanged()
+	if tag := h.app.imagesPanel.SelectedTag(); tag != "nginx:latest" {
+		t.Errorf("selected tag after untag = %q", tag)
+	}
+}
+
+func TestBatchActionsOnMarkedContainers(t *testing.T) {
+	fake := seeded()
+	fake.AddContainer(docker.ContainerInfo{ID: "cache1", Name: "cache", Image: "redis", State: "running"})
+	h := newHarness(t, fake)
+
+	h.send(tea.KeyMsg{Type: tea.KeyCtrlA})
+	if got := len(h.app.containersPanel.Marked()); got != 3 {
+		t.Fatalf("marked = %d, want all 3", got)
+	}
+	h.key("x") // stop applies to the running ones only
+	h.settle()
+
+	stopped := map[any]bool{}
+	for _, c := range h.fake.Calls("StopContainer") {
+		stopped[c.Args[0]] = true
+	}
+	if len(stopped) != 2 || !stopped["web1"] || !stopped["cache1"] {
+		t.Fatalf("stopped = %v, want web1 and cache1", stopped)
+	}
+	if len(h.app.containersPanel.Marked()) != 0 {
+		t.Error("marks should clear after a batch action")
+	}
+	if !strings.Contains(h.app.notice, "stop: 2 containers ok") {
+		t.Errorf("notice = %q", h.app.notice)
+	}
+}
+
+func TestBatchReportsPerContainerFailures(t *testing.T) {
+	fake := seeded()
+	fake.AddContainer(docker.ContainerInfo{ID: "cache1", Name: "cache", Image: "redis", State: "exited"})
+	h := newHarness(t, fake)
+
+	h.key("j") // cache (exited, by name before db)
+	h.key(" ") // mark cache, move to db
+	h.key(" ") // mark db
+	if got := len(h.app.containersPanel.Marked()); got != 2 {
+		t.Fatalf("marked = %d, want 2", got)
+	}
+
+	h.fake.FailOn("RemoveContainer", errors.New("device busy"))
+	h.key("d")
+	if view := h.app.View(); !strings.Contains(view, "Delete 2 containers?") {
+		t.Fatalf("dialog should count the targets:\n%s", view)
+	}
+	h.key("y")
+	h.settle()
+
+	if got := len(h.fake.Calls("RemoveContainer")); got != 2 {
+		t.Fatalf("RemoveContainer called %d times, want 2", got)
+	}
+	if h.app.err == nil || !strings.Contains(h.app.err.Error(), "0 ok, 2 failed") ||
+		!strings.Contains(h.app.err.Error(), "cache: device busy") {
+		t.Fatalf("err = %v, want per-container failures", h.app.err)
+	}
+}
+
+func TestPauseTogglesAndGuardsStartStop(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.key("p") // web is running
+	h.settle()
+	h.fake.Emit(docker.Event{Kind: docker.EventContainer, Action: "pause", ID: "web1"})
+	h.settle()
+	if got := len(h.fake.Calls("PauseContainer")); got != 1 {
+		t.Fatalf("PauseContainer called %d times, want 1", got)
+	}
+	if c := h.container("web1"); c.State != "paused" {
+		t.Fatalf("web state = %q, want paused", c.State)
+	}
+
+	h.key("s") // a paused container can't be started
+	h.settle()
+	if got := len(h.fake.Calls("StartContainer")); got != 0 {
+		t.Errorf("StartContainer called %d times on a paused container", got)
+	}
+
+	h.key("p")
+	h.settle()
+	h.fake.Emit(docker.Event{Kind: docker.EventContainer, Action: "unpause", ID: "web1"})
+	h.settle()
+	if got := len(h.fake.Calls("UnpauseContainer")); got != 1 {
+		t.Fatalf("UnpauseContainer called %d times, want 1", got)
+	}
+	if c := h.container("web1"); c.State != "running" {
+		t.Fatalf("web state = %q, want running", c.State)
+	}
+
+	h.key("p")
+	h.settle()
+	h.fake.Emit(docker.Event{Kind: docker.EventContainer, Action: "pause", ID: "web1"})
+	h.settle()
+	h.key("x") // paused containers can be stopped
+	h.settle()
+	if got := len(h.fake.Calls("StopContainer")); got != 1 {
+		t.Errorf("StopContainer called %d times on a paused container, want 1", got)
+	}
+}
+
+func TestPauseBatchNamesEachAction(t *testing.T) {
+	fake := seeded()
+	fake.AddContainer(docker.ContainerInfo{ID: "cache1", Name: "cache", Image: "redis", State: "paused"})
+	fake.FailOn("UnpauseContainer", errors.New("device busy"))
+	h := newHarness(t, fake)
+
+	h.send(tea.KeyMsg{Type: tea.KeyCtrlA})
+	h.key("p") // web is running, cache is paused, db is skipped
+	h.settle()
+	if h.app.err == nil || !strings.Contains(h.app.err.Error(), "pause 1, unpause 1: 1 ok, 1 failed") ||
+		!strings.Contains(h.app.err.Error(), "cache: unpause: device busy") {
+		t.Fatalf("err = %v, want the action of each container", h.app.err)
+	}
+}
+
+func TestKillSendsPickedSignal(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.key("K")
+	if view := h.app.View(); !strings.Contains(view, "SIGHUP") || !strings.Contains(view, "(•) SIGKILL") {
+		t.Fatalf("signal picker should list signals with SIGKILL selected:\n%s", view)
+	}
+	h.send(tea.KeyMsg{Type: tea.KeyUp}) // SIGUSR2
+	h.send(tea.KeyMsg{Type: tea.KeyUp}) // SIGUSR1
+	h.key("y")
+	h.settle()
+
+	calls := h.fake.Calls("KillContainer")
+	if len(calls) != 1 || calls[0].Args[0] != "web1" || calls[0].Args[1] != "SIGUSR1" {
+		t.Fatalf("KillContainer calls = %v, want web1 SIGUSR1", calls)
+	}
+}
+
+func TestStopTimeoutFromConfig(t *testing.T) {
+	h := newHarness(t, seeded())
+	h.app.config.StopTimeouts = []config.StopRule{{Pattern: "we*", Timeout: 60}}
+
+	h.key("x")
+	h.settle()
+	h.key("r")
+	h.settle()
+
+	for _, method := range []string{"StopContainer", "RestartContainer"} {
+		calls := h.fake.Calls(method)
+		if len(calls) != 1 || calls[0].Args[1] != 60*time.Second {
+			t.Errorf("%s calls = %v, want web1 with a 60s timeout", method, calls)
+		}
+	}
+}
+
+func TestShellNeedsRunningContainer(t *testing.T) {
+	h := newHarness(t, seeded())
+
+	h.key("j") // db is exited
+	h.key("e")
+	if h.app.err == nil || !strings.Contains(h.app.err.Error(), "db is not running") {
+		t.Fatalf("err = %v, want a not running error", h.app.err)
+	}
+
+	h.send(shellExitedMsg{name: "web", err: errors.New("no shell found in container")})
+	if h.app.err == nil || !strings.Contains(h.app.err.Error(), "exec in web: no shell found") {
+		t.Fatalf("err = %v, want the exec failure", h.app.err)
+	}
+}
+
+func TestProcessesViewSignalsProcess(t *testing.T) {
+	fake := seeded()
+	fake.SetProcesses("web1", []docker.ProcessInfo{
+		{PID: 100, User: "root", CPUPerc: 0.1, Command: "nginx: master process"},
+		{PID: 120, User: "www-data", CPUPerc: 87.5, Command: "nginx: worker process"},
+	})
+	h := newHarness(t, fake)
+
+	h.key("t")
+	h.settle()
+	view := h.app.View()
+	if !strings.Contains(view, "Processes") || !strings.Contains(view, "2 processes") {
+		t.Fatalf("processes view not shown:\n%s", view)
+	}
+	if !strings.Contains(view, "worker process") || strings.Index(view, "worker process") > strings.Index(view, "master process") {
+		t.Fatalf("the busiest process should be listed first:\n%s", view)
+	}
+
+	h.key("j") // master
+	h.key("K")
+	h.send(tea.KeyMsg{Type: tea.KeyDown}) // SIGINT
+	h.send(tea.KeyMsg{Type: tea.KeyDown}) // SIGHUP
+	h.key("y")
+	h.settle()
+
+	calls := h.fake.Calls("SignalProcess")
+	if len(calls) != 1 || calls[0].Args[0] != "web1" || calls[0].Args[1] != 100 || calls[0].Args[2] != "SIGHUP" {
+		t.Fatalf("SignalProcess calls = %v, want web1 100 SIGHUP", calls)
+	}
+	if h.app.notice != "sent SIGHUP to PID 100" {
+		t.Errorf("notice = %q", h.app.notice)
+	}
+
+	h.send(tea.KeyMsg{Type: tea.KeyEsc})
+	if h.app.activePanel != PanelContainers {
+		t.Errorf("Esc should return to the containers panel")
+	}
+}
+
+func TestProcessesStopPollingWhenContainerExits(t *testing.T) {
+	fake := seeded()
+	fake.SetProcesses("web1", []docker.ProcessInfo{{PID: 100, Command: "nginx"}})
+	h := newHarness(t, fake)
+
+	h.key("t")
+	h.settle()
+	if err := h.fake.StopContainer(context.Background(), "web1", 0); err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		h.send(tickMsg(time.Now()))
+		h.settle()
+	}
+
+	if got := len(h.fake.Calls("ListProcesses")); got != 2 {
+		t.Errorf("ListProcesses called %d times, want 2 (open, then the tick that failed)", got)
+	}
+	if h.app.err != nil {
+		t.Errorf("err = %v, want the error shown in the panel only", h.app.err)
+	}
+	if view := ansi.Strip(h.app.View()); !strings.Contains(view, "is not running") || !strings.Contains(view, "No longer updating") {
+		t.Fatalf("stop reason not shown:\n%s", view)
+	}
+}
+
+func TestDiffViewShowsChanges(t *testing.T) {
+	fake := seeded()
+	fake.SetDiff("web1", docker.FilesystemDiff{
+		SizeRw: 1 << 20,
+		Changes: []container.FilesystemChange{
+			{Kind: container.ChangeModify, Path: "/var"},
+			{Kind: container.ChangeAdd, Path: "/var/cache.db"},
+		},
+	})
+	h := newHarness(t, fake)
+
+	h.key("c")
+	h.settle()
+	view := ansi.Strip(h.app.View())
+	if !strings.Contains(view, "Changes") || !strings.Contains(view, "cache.db") || !strings.Contains(view, "1.0MB") {
+		t.Fatalf("diff view not shown:\n%s", view)
+	}
+
+	h.key("c")
+	if h.app.activePanel != PanelContainers {
+		t.Errorf("c should return to the containers panel")
+	}
+}
+
+func TestFilesViewDownloadsAndUploads(t *testing.T) {
+	fake := seeded()
+	fake.AddFile("web1", "/etc/nginx/nginx.conf", "worker_processes 1;")
+	fake.AddFile("web1", "/etc/hosts", "127.0.0.1 localhost")
+	h := newHarness(t, fake)
+	local := t.TempDir()
+
+	h.key("f")
+	h.settle()
+	h.key("enter")
+	h.settle()
+	view := ansi.Strip(h.app.View())
+	if !strings.Contains(view, "nginx/") || !strings.Contains(view, "hosts") {
+		t.Fatalf("/etc not listed:\n%s", view)
+	}
+
+	// Directories come first; hosts is the second entry
+	h.key("j")
+	h.key("d")
+	if h.app.mode != ModePrompt {
+		t.Fatalf("d should ask where to download to")
+	}
+	h.app.prompt.input.SetValue(filepath.Join(local, "hosts"))
+	h.key("enter")
+	h.settle()
+	got, err := os.ReadFile(filepath.Join(local, "hosts"))
+	if err != nil || string(got) != "127.0.0.1 localhost" {
+		t.Fatalf("downloaded hosts = %q, %v (err shown: %v)", got, err, h.app.err)
+	}
+
+	src := filepath.Join(local, "app.env")
+	if err := os.WriteFile(src, []byte("MODE=prod"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h.key("u")
+	h.app.prompt.input.SetValue(src)
+	h.key("enter")
+	h.settle()
+	if content, ok := fake.File("web1", "/etc/app.env"); !ok || content != "MODE=prod" {
+		t.Fatalf("uploaded file = %q, %v (err shown: %v)", content, ok, h.app.err)
+	}
+	if view := ansi.Strip(h.app.View()); !strings.Contains(view, "app.env") {
+		t.Errorf("listing not reloaded after upload:\n%s", view)
+	}
+
+	h.key("h")
+	h.settle()
+	if entry := h.app.filesPanel.GetSelected(); h.app.filesPanel.Dir() != "/" || entry == nil || entry.Name != "etc" {
+		t.Errorf("going up should land on etc in /, got %s %+v", h.app.filesPanel.Dir(), entry)
+	}
+}
+
+func TestRunFormCreatesContainerAndSavesPreset(t *testing.T) {
+	fake := seeded()
+	h := newHarness(t, fake)
+
+	h.send(tea.KeyMsg{Type: tea.KeyTab}) // images panel
+	h.key("r")
+	if h.app.mode != ModeRunForm {
+		t.Fatalf("r should open the run form")
+	}
+	form := h.app.runForm
+	form.name.input.SetValue("web2")
+	form.env.input.SetValue(`MODE=prod "GREETING=hello world"`)
+	form.ports.input.SetValue("8080:80")
+	form.saveAs.input.SetValue("nginx-dev")
+	h.send(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if presets := h.app.config.PresetsFor("nginx:latest"); len(presets) != 1 || presets[0].ContainerName != "web2" {
+		t.Fatalf("preset not saved: %+v (form error %q)", presets, form.err)
+	}
+
+	h.send(tea.KeyMsg{Type: tea.KeyEnter})
+	h.settle()
+	calls := fake.Calls("CreateContainer")
+	if len(calls) != 1 {
+		t.Fatalf("CreateContainer called %d times (err shown: %v)", len(calls), h.app.err)
+	}
+	opts := calls[0].Args[0].(docker.RunOptions)
+	if opts.Image != "nginx:latest" || len(opts.Env) != 2 || opts.Env[1] != "GREETING=hello world" || opts.Ports[0] != "8080:80" {
+		t.Errorf("run options = %+v", opts)
+	}
+	if c, ok := fake.Container("web2"); !ok || c.State != "running" {
+		t.Errorf("web2 not started: %+v", c)
+	}
+	if h.app.activePanel != PanelContainers || h.app.notice != "started web2" {
+		t.Errorf("panel %v, notice %q", h.app.activePanel, h.app.notice)
+	}
+
+	// The preset is offered the next time and fills the form
+	h.send(tea.KeyMsg{Type: tea.KeyTab})
+	h.key("r")
+	h.send(tea.KeyMsg{Type: tea.KeyRight})
+	if got := h.app.runForm.ports.value(); got != "8080:80" {
+		t.Errorf("preset not applied, ports = %q", got)
+	}
+	h.send(tea.KeyMsg{Type: tea.KeyEsc})
+	if h.app.mode != ModeNormal || len(fake.Calls("CreateContainer")) != 1 {
+		t.Errorf("Esc should close the form without running")
+	}
+}
+
+func TestPullShowsProgressAndErrors(t *testing.T) {
+	fake := seeded()
+	fake.SetPullOutput("redis:7", strings.Join([]string{
+		`{"status":"Pulling from library/redis","id":"7"}`,
+		`{"status":"Downloading","progressDetail":{"current":512,"total":1024},"id":"layer1"}`,
+		`{"status":"Pull complete","progressDetail":{},"id":"layer1"}`,
+		`{"status":"Digest: sha256:abc"}`,
+	}, "\n"), false)
+	fake.SetPullOutput("private/app:1", `{"errorDetail":{"message":"pull access denied for private/app"},"error":"pull access denied for private/app"}`, false)
+	fake.SetPullOutput("huge:latest", `{"status":"Downloading","progressDetail":{"current":1,"total":1073741824},"id":"layer9"}`, true)
+	h := newHarness(t, fake)
+
+	h.send(tea.KeyMsg{Type: tea.KeyTab}) // images panel
+	pull := func(ref string) {
+		if h.app.activePanel == PanelPull {
+			h.send(tea.KeyMsg{Type: tea.KeyEsc}) // back to the images
+		}
+		h.key("p")
+		h.app.pullInput.SetValue(ref)
+		h.send(tea.KeyMsg{Type: tea.KeyEnter})
+		h.settle()
+	}
+
+	pull("redis:7")
+	view := ansi.Strip(h.app.View())
+	if h.app.activePanel != PanelPull || !strings.Contains(view, "layer1") || !strings.Contains(view, "done") {
+		t.Fatalf("pull view not shown:\n%s", view)
+	}
+	if h.app.notice != "pulled redis:7" {
+		t.Errorf("notice = %q", h.app.notice)
+	}
+
+	pull("private/app:1")
+	if h.app.err == nil || !strings.Contains(h.app.err.Error(), "pull access denied") {
+		t.Errorf("stream error not surfaced: %v", h.app.err)
+	}
+	if view := ansi.Strip(h.app.View()); !strings.Contains(view, "failed") {
+		t.Errorf("pull not shown as failed:\n%s", view)
+	}
+
+	pull("huge:latest")
+	if view := ansi.Strip(h.app.View()); !strings.Contains(view, "pulling") || !strings.Contains(view, "layer9") {
+		t.Fatalf("running pull not shown:\n%s", view)
+	}
+	h.key("x")
+	h.settle()
+	if h.app.notice != "pull of huge:latest cancelled" || h.app.pullPanel.Count(PullActive) != 0 {
+		t.Errorf("pull not cancelled, notice %q", h.app.notice)
+	}
+}
+
+func TestPullQueueRunsConcurrentlyAndRetries(t *testing.T) {
+	fake := seeded()
+	for _, ref := range []string{"a:1", "b:1", "c:1"} {
+		fake.SetPullOutput(ref, `{"status":"Downloading","progressDetail":{"current":1,"total":100},"id":"`+ref+`"}`, true)
+	}
+	fake.SetPullOutput("flaky:1", `{"errorDetail":{"message":"TLS handshake timeout"},"error":"TLS handshake timeout"}`, false)
+	h := newHarness(t, fake)
+
+	h.send(tea.KeyMsg{Type: tea.KeyTab}) // images panel
+	h.key("p")
+	h.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a:1\nb:1\nc:1\nflaky:1\n"), Paste: true})
+	h.send(tea.KeyMsg{Type: tea.KeyEnter})
+	h.settle()
+
+	// Three pulls run by default; the fourth waits its turn
+	queue := h.app.pullPanel
+	if len(queue.Jobs()) != 4 || queue.Count(PullActive) != 3 || queue.Count(PullQueued) != 1 {
+		t.Fatalf("queue = %d jobs, %d active, %d queued", len(queue.Jobs()), queue.Count(PullActive), queue.Count(PullQueued))
+	}
+	if view := ansi.Strip(h.app.View()); !strings.Contains(view, "3 pulling, 1 queued") {
+		t.Errorf("queue counts not shown:\n%s", view)
+	}
+
+	// Cancelling one frees a slot for the queued pull, which fails
+	h.key("x")
+	h.settle()
+	if got := queue.Jobs()[0].State; got != PullCancelled {
+		t.Errorf("a:1 is %s, want cancelled", got)
+	}
+	flaky := queue.Jobs()[3]
+	if flaky.State != PullFailed || len(fake.Calls("PullImage")) != 4 {
+		t.Fatalf("flaky:1 is %s after %d pulls", flaky.State, len(fake.Calls("PullImage")))
+	}
+	if view := ansi.Strip(h.app.View()); !strings.Contains(view, "TLS handshake timeout") {
+		t.Errorf("failure not shown:\n%s", view)
+	}
+
+	// R retries the failed pulls only
+	fake.SetPullOutput("flaky:1", `{"status":"Pull complete","progressDetail":{},"id":"layer1"}`, false)
+	h.key("R")
+	h.settle()
+	if flaky.State != PullDone || h.app.notice != "pulled flaky:1" {
+		t.Errorf("retry: flaky:1 is %s, notice %q", flaky.State, h.app.notice)
+	}
+	if got := queue.Jobs()[0].State; got != PullCancelled {
+		t.Errorf("R retried the cancelled a:1: %s", got)
+	}
+
+	// r retries the selected pull, whether it failed or was cancelled
+	h.key("r")
+	h.settle()
+	if got := queue.Jobs()[0].State; got != PullActive {
+		t.Errorf("a:1 is %s after r, want pulling", got)
+	}
+
+	h.key("c")
+	if len(queue.Jobs()) != 3 {
+		t.Errorf("c should clear the finished pull, %d left", len(queue.Jobs()))
+	}
+}
+
+func TestLoginStoresCredentialsForPulls(t *testing.T) {
+	fake := seeded()
+	fake.AddImage(docker.ImageInfo{ID: "sha256:api", Tags: []string{"registry.example.com/acme/api:1"}})
+	fake.SetPullOutput("registry.example.com/acme/api:2", `{"errorDetail":{"message":"unauthorized: authentication required"}}`, false)
+	h := newHarness(t, fake)
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	h.send(tea.KeyMsg{Type: tea.KeyTab}) // images panel
+	h.key("p")
+	h.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("registry.example.com/acme/api:2")})
+	h.send(tea.KeyMsg{Type: tea.KeyEnter})
+	h.settle()
+	if h.app.err == nil || !strings.Contains(h.app.err.Error(), "L logs in") {
+		t.Fatalf("pull error should point at the login form: %v", h.app.err)
+	}
+
+	// L in the pull view logs in to the registry of the selected pull
+	login := func(user, password string) {
+		h.key("L")
+		if h.app.mode != ModeLogin {
+			t.Fatal("L should open the login form")
+		}
+		h.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(user)})
+		h.send(tea.KeyMsg{Type: tea.KeyTab})
+		h.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(password)})
+		h.send(tea.KeyMsg{Type: tea.KeyEnter})
+		h.settle()
+	}
+
+	fake.FailOn("Login", errors.New("unauthorized: incorrect username or password"))
+	login("ci", "wrong")
+	if h.app.err == nil || !strings.Contains(h.app.err.Error(), "login to registry.example.com") {
+		t.Errorf("failed login not reported: %v", h.app.err)
+	}
+	if _, ok, _ := docker.LookupCredentials("registry.example.com"); ok {
+		t.Error("rejected credentials were saved")
+	}
+
+	fake.FailOn("Login", nil)
+	login("ci", "s3cret")
+	if h.app.notice != "logged in to registry.example.com" {
+		t.Errorf("notice = %q, err %v", h.app.notice, h.app.err)
+	}
+	calls := fake.Calls("Login")
+	if creds := calls[len(calls)-1].Args[0].(docker.Credentials); creds.Server != "registry.example.com" || creds.Username != "ci" || creds.Password != "s3cret" {
+		t.Errorf("login with %+v", creds)
+	}
+	creds, ok, err := docker.LookupCredentials("registry.example.com")
+	if err != nil || !ok || creds.Username != "ci" || creds.Password != "s3cret" {
+		t.Errorf("saved credentials: %+v, %v, %v", creds, ok, err)
+	}
+}
+
+func TestImageInfoShowsLayersAndConfig(t *testing.T) {
+	fake := seeded()
+	now := time.Now()
+	fake.SetImageDetail("sha256:nginx", docker.ImageDetail{
+		Architecture: "amd64",
+		OS:           "linux",
+		Entrypoint:   []string{"/docker-entrypoint.sh"},
+		Cmd:          []string{"nginx", "-g", "daemon off;"},
+		Env:          []string{"NGINX_VERSION=1.27.0", "API_TOKEN=abc"},
+		ExposedPorts: []string{"80/tcp"},
+		Labels:       map[string]string{"maintainer": "NGINX Docker Maintainers"},
+		Layers: []docker.ImageLayer{
+			{ID: "sha256:nginx", CreatedBy: `CMD ["nginx" "-g" "daemon off;"]`, Created: now.Add(-2 * time.Hour)},
+			{ID: "<missing>", CreatedBy: "COPY docker-entrypoint.sh / # buildkit", Size: 1 << 10, Created: now.Add(-2 * time.Hour)},
+			{ID: "<missing>", CreatedBy: "RUN apt-get install -y nginx", Size: 60 << 20, Created: now.Add(-2 * time.Hour)},
+			{ID: "<missing>", CreatedBy: "RUN apt-get update", Size: 20 << 20, Created: now.Add(-2 * time.Hour)},
+			{ID: "<missing>", CreatedBy: "ENV NGINX_VERSION=1.27.0", Created: now.Add(-72 * time.Hour)},
+			{ID: "<missing>", CreatedBy: "ADD file:debian in /", Size: 75 << 20, Created: now.Add(-72 * time.Hour)},
+		},
+	})
+	h := newHarness(t, fake)
+
+	h.send(tea.KeyMsg{Type: tea.KeyTab}) // images panel
+	h.send(tea.KeyMsg{Type: tea.KeyEnter})
+	h.settle()
+	if h.app.activePanel != PanelImageInfo {
+		t.Fatalf("Enter should open the image view, panel %v", h.app.activePanel)
+	}
+
+	view := ansi.Strip(h.app.View())
+	for _, want := range []string{"nginx:latest", "RUN apt-get install -y nginx", "2 hours ago", "60.0MB", "/docker-entrypoint.sh", "80/tcp", "amd64", "linux"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("image view lacks %q:\n%s", want, view)
+		}
+	}
+
+	var hot []string
+	for _, l := range h.app.imageInfoPanel.lines {
+		if l.hot {
+			hot = append(hot, l.text)
+		}
+	}
+	if len(hot) != 3 || !strings.Contains(hot[0], "apt-get install") || !strings.Contains(hot[2], "file:debian") {
+		t.Errorf("the three largest layers should stand out, got %q", hot)
+	}
+
+	// Env vars that look like secrets are masked as in the container view
+	if !strings.Contains(view, "API_TOKEN="+maskedValue) || strings.Contains(view, "abc") {
+		t.Errorf("env not shown or not masked:\n%s", view)
+	}
+
+	h.send(tea.KeyMsg{Type: tea.KeyEsc})
+	if h.app.activePanel != PanelImages {
+		t.Errorf("Esc should return to the images, panel %v", h.app.activePanel)
+	}
+}
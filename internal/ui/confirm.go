@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// confirmToggle is an on/off option of a confirmation dialog, switched with
+// its key
+type confirmToggle struct {
+	key   string
+	label string
+	on    bool
+}
+
+// ConfirmDialog asks before a destructive action. It names the target,
+// offers toggles (force, remove volumes) and an optional choice between
+// alternative actions (untag or delete), and runs onConfirm with the
+// dialog's final state.
+type ConfirmDialog struct {
+	title   string
+	target  string
+	notes   []string
+	toggles []*confirmToggle
+	choices []string
+	choice  int
+
+	onConfirm func(d *ConfirmDialog) tea.Cmd
+}
+
+func NewConfirmDialog(title, target string, onConfirm func(d *ConfirmDialog) tea.Cmd) *ConfirmDialog {
+	return &ConfirmDialog{
+		title:     title,
+		target:    target,
+		onConfirm: onConfirm,
+	}
+}
+
+// AddNote adds a line of context, e.g. a warning about the target's state
+func (d *ConfirmDialog) AddNote(note string) *ConfirmDialog {
+	d.notes = append(d.notes, note)
+	return d
+}
+
+// AddToggle adds an option switched with key
+func (d *ConfirmDialog) AddToggle(key, label string, on bool) *ConfirmDialog {
+	d.toggles = append(d.toggles, &confirmToggle{key: key, label: label, on: on})
+	return d
+}
+
+// SetChoices sets mutually exclusive variants of the action, cycled with
+// Tab or the arrow keys
+func (d *ConfirmDialog) SetChoices(selected int, choices ...string) *ConfirmDialog {
+	d.choices = choices
+	d.choice = selected
+	return d
+}
+
+// Toggle reports whether the option with key is on
+func (d *ConfirmDialog) Toggle(key string) bool {
+	for _, t := range d.toggles {
+		if t.key == key {
+			return t.on
+		}
+	}
+	return false
+}
+
+// Choice returns the index of the selected variant
+func (d *ConfirmDialog) Choice() int {
+	return d.choice
+}
+
+// HandleKey processes a key press. It returns done once the dialog should
+// close, along with the action to run if it was confirmed.
+func (d *ConfirmDialog) HandleKey(key string) (done bool, cmd tea.Cmd) {
+	switch key {
+	case "y", "enter":
+		return true, d.onConfirm(d)
+	case "n", "esc", "q":
+		return true, nil
+	case "tab", "right", "l", "down", "j":
+		if len(d.choices) > 0 {
+			d.choice = (d.choice + 1) % len(d.choices)
+		}
+	case "shift+tab", "left", "h", "up", "k":
+		if len(d.choices) > 0 {
+			d.choice = (d.choice + len(d.choices) - 1) % len(d.choices)
+		}
+	default:
+		for _, t := range d.toggles {
+			if t.key == key {
+				t.on = !t.on
+			}
+		}
+	}
+	return false, nil
+}
+
+func (d *ConfirmDialog) View() string {
+	lines := []string{
+		theme.StoppedStyle.Render(d.title),
+		"",
+		theme.HighlightStyle.Render(d.target),
+	}
+	for _, note := range d.notes {
+		lines = append(lines, theme.PausedStyle.Render(note))
+	}
+
+	if len(d.choices) > 0 {
+		lines = append(lines, "")
+		for i, c := range d.choices {
+			line := "( ) " + c
+			if i == d.choice {
+				line = theme.SelectedStyle.Render("(•) " + c)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	if len(d.toggles) > 0 {
+		lines = append(lines, "")
+		for _, t := range d.toggles {
+			box := "[ ]"
+			if t.on {
+				box = "[x]"
+			}
+			lines = append(lines, theme.HelpKeyStyle.Render(t.key)+" "+box+" "+t.label)
+		}
+	}
+
+	hints := []string{
+		theme.HelpKeyStyle.Render("y/Enter") + theme.HelpStyle.Render(":confirm"),
+		theme.HelpKeyStyle.Render("n/Esc") + theme.HelpStyle.Render(":cancel"),
+	}
+	if len(d.choices) > 0 {
+		hints = append(hints, theme.HelpKeyStyle.Render("Tab")+theme.HelpStyle.Render(":switch"))
+	}
+	lines = append(lines, "", strings.Join(hints, "  "))
+
+	box := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Red).
+		Padding(1, 3)
+
+	return box.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -2,9 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/seb07-cloud/dktop/internal/docker"
 	"github.com/seb07-cloud/dktop/internal/theme"
 )
@@ -17,10 +20,91 @@ type ContainersPanel struct {
 	offset     int
 	active     bool
 	filter     string
+	showNet    bool
+	showIO     bool
+
+	// selectedID keeps the cursor on the same container when the list is
+	// refreshed or re-sorted
+	selectedID string
+	sortBy     string
+	sortDesc   bool
+
+	// IDs of containers marked for batch actions
+	marked map[string]bool
+}
+
+// Sort columns, in the order < and > cycle through them
+const (
+	SortName   = "name"
+	SortState  = "state"
+	SortCPU    = "cpu"
+	SortMem    = "mem"
+	SortNet    = "net"
+	SortUptime = "uptime"
+	SortImage  = "image"
+)
+
+var sortColumns = []string{SortName, SortState, SortCPU, SortMem, SortNet, SortUptime, SortImage}
+
+// stateRank orders states from most to least alive
+var stateRank = map[string]int{
+	"running":    0,
+	"restarting": 1,
+	"paused":     2,
+	"created":    3,
+	"exited":     4,
+	"dead":       5,
 }
 
 func NewContainersPanel() *ContainersPanel {
-	return &ContainersPanel{}
+	return &ContainersPanel{sortBy: SortState, marked: make(map[string]bool)}
+}
+
+// SetSort sets the sort column and direction. Unknown columns fall back to
+// sorting by name.
+func (p *ContainersPanel) SetSort(column string, desc bool) {
+	column = strings.ToLower(column)
+	p.sortBy = SortName
+	for _, c := range sortColumns {
+		if c == column {
+			p.sortBy = column
+			break
+		}
+	}
+	p.sortDesc = desc
+	p.resort()
+}
+
+// CycleSort moves the sort to the next (delta 1) or previous (delta -1) column
+func (p *ContainersPanel) CycleSort(delta int) {
+	i := 0
+	for j, c := range sortColumns {
+		if c == p.sortBy {
+			i = j
+			break
+		}
+	}
+	i = (i + delta + len(sortColumns)) % len(sortColumns)
+	p.SetSort(sortColumns[i], p.sortDesc)
+}
+
+// ToggleSortOrder switches between ascending and descending
+func (p *ContainersPanel) ToggleSortOrder() {
+	p.SetSort(p.sortBy, !p.sortDesc)
+}
+
+// SetColumns enables the optional columns named in cols ("net", "io")
+func (p *ContainersPanel) SetColumns(cols []string) {
+	p.showNet = false
+	p.showIO = false
+	for _, c := range cols {
+		switch strings.ToLower(c) {
+		case "net":
+			p.showNet = true
+		case "io":
+			p.showIO = true
+		}
+	}
 }
 
 func (p *ContainersPanel) SetSize(width, height int) {
@@ -33,20 +117,170 @@ func (p *ContainersPanel) SetActive(active bool) {
 }
 
 func (p *ContainersPanel) Update(containers []docker.ContainerInfo) {
-	p.containers = containers
-	// Ensure selection is valid
-	if p.selected >= len(p.containers) {
-		p.selected = len(p.containers) - 1
+	p.containers = append([]docker.ContainerInfo(nil), containers...)
+
+	// Forget marks of containers that are gone
+	present := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		present[c.ID] = true
 	}
-	if p.selected < 0 {
-		p.selected = 0
+	for id := range p.marked {
+		if !present[id] {
+			delete(p.marked, id)
+		}
+	}
+
+	p.resort()
+}
+
+// ToggleMark marks or unmarks the selected container and moves down
+func (p *ContainersPanel) ToggleMark() {
+	selected := p.GetSelected()
+	if selected == nil {
+		return
+	}
+	if p.marked[selected.ID] {
+		delete(p.marked, selected.ID)
+	} else {
+		p.marked[selected.ID] = true
+	}
+	p.MoveDown()
+}
+
+// MarkAll marks every container matching the filter
+func (p *ContainersPanel) MarkAll() {
+	for _, c := range p.GetFiltered() {
+		p.marked[c.ID] = true
+	}
+}
+
+// InvertMarks flips the mark of every container matching the filter
+func (p *ContainersPanel) InvertMarks() {
+	for _, c := range p.GetFiltered() {
+		if p.marked[c.ID] {
+			delete(p.marked, c.ID)
+		} else {
+			p.marked[c.ID] = true
+		}
+	}
+}
+
+func (p *ContainersPanel) ClearMarks() {
+	p.marked = make(map[string]bool)
+}
+
+// Marked returns the marked containers in display order, including ones
+// hidden by the filter
+func (p *ContainersPanel) Marked() []docker.ContainerInfo {
+	var marked []docker.ContainerInfo
+	for _, c := range p.containers {
+		if p.marked[c.ID] {
+			marked = append(marked, c)
+		}
 	}
+	return marked
 }
 
 func (p *ContainersPanel) SetFilter(filter string) {
 	p.filter = filter
 	p.selected = 0
 	p.offset = 0
+	p.rememberSelection()
+}
+
+// resort sorts the list and moves the cursor back onto the container it
+// was on, if that container is still listed
+func (p *ContainersPanel) resort() {
+	less := p.lessFunc()
+	sort.SliceStable(p.containers, func(i, j int) bool {
+		a, b := p.containers[i], p.containers[j]
+		if less(a, b) {
+			return !p.sortDesc
+		}
+		if less(b, a) {
+			return p.sortDesc
+		}
+		// Ties are always broken by name, ascending
+		return a.Name < b.Name
+	})
+
+	filtered := p.GetFiltered()
+	for i, c := range filtered {
+		if c.ID == p.selectedID {
+			p.selected = i
+			break
+		}
+	}
+	if p.selected >= len(filtered) {
+		p.selected = len(filtered) - 1
+	}
+	if p.selected < 0 {
+		p.selected = 0
+	}
+	p.rememberSelection()
+	p.scrollToSelected()
+}
+
+func (p *ContainersPanel) lessFunc() func(a, b docker.ContainerInfo) bool {
+	switch p.sortBy {
+	case SortState:
+		return func(a, b docker.ContainerInfo) bool {
+			return rankState(a.State) < rankState(b.State)
+		}
+	case SortCPU:
+		return func(a, b docker.ContainerInfo) bool { return a.CPUPerc < b.CPUPerc }
+	case SortMem:
+		return func(a, b docker.ContainerInfo) bool { return a.MemUsage < b.MemUsage }
+	case SortNet:
+		return func(a, b docker.ContainerInfo) bool {
+			return a.NetRxRate+a.NetTxRate < b.NetRxRate+b.NetTxRate
+		}
+	case SortUptime:
+		// Stopped containers count as zero uptime
+		return func(a, b docker.ContainerInfo) bool { return uptime(a) < uptime(b) }
+	case SortImage:
+		return func(a, b docker.ContainerInfo) bool { return a.Image < b.Image }
+	default:
+		return func(a, b docker.ContainerInfo) bool { return a.Name < b.Name }
+	}
+}
+
+func rankState(state string) int {
+	if r, ok := stateRank[state]; ok {
+		return r
+	}
+	return len(stateRank)
+}
+
+func uptime(c docker.ContainerInfo) time.Duration {
+	if c.State != "running" || c.StartedAt.IsZero() {
+		return 0
+	}
+	return time.Since(c.StartedAt)
+}
+
+func (p *ContainersPanel) rememberSelection() {
+	p.selectedID = ""
+	if c := p.GetSelected(); c != nil {
+		p.selectedID = c.ID
+	}
+}
+
+// scrollToSelected keeps the cursor row inside the visible window
+func (p *ContainersPanel) scrollToSelected() {
+	visibleRows := p.height - 5
+	if visibleRows < 1 {
+		visibleRows = 1
+	}
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+	if p.selected >= p.offset+visibleRows {
+		p.offset = p.selected - visibleRows + 1
+	}
+	if p.offset < 0 {
+		p.offset = 0
+	}
 }
 
 func (p *ContainersPanel) GetFiltered() []docker.ContainerInfo {
@@ -72,6 +306,7 @@ func (p *ContainersPanel) MoveUp() {
 		if p.selected < p.offset {
 			p.offset = p.selected
 		}
+		p.rememberSelection()
 	}
 }
 
@@ -83,6 +318,7 @@ func (p *ContainersPanel) MoveDown() {
 		if p.selected >= p.offset+visibleRows {
 			p.offset = p.selected - visibleRows + 1
 		}
+		p.rememberSelection()
 	}
 }
 
@@ -104,6 +340,9 @@ func (p *ContainersPanel) View() string {
 	if p.filter != "" {
 		title += theme.InactiveStyle.Render(fmt.Sprintf(" [filter: %s]", p.filter))
 	}
+	if len(p.marked) > 0 {
+		title += theme.MarkedStyle.Render(fmt.Sprintf(" [%d marked]", len(p.marked)))
+	}
 
 	filtered := p.GetFiltered()
 
@@ -112,39 +351,45 @@ func (p *ContainersPanel) View() string {
 		return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n\n" + content)
 	}
 
-	// Calculate column widths - use proportional widths based on panel width
-	availableWidth := p.width - 10 // Account for borders and padding
-
-	nameW := availableWidth * 18 / 100   // 18%
-	statusW := availableWidth * 12 / 100 // 12%
-	cpuW := 8                             // Fixed width for CPU %
-	memW := 10                            // Fixed width for memory
-	portsW := availableWidth * 22 / 100  // 22%
-	imageW := availableWidth - nameW - statusW - cpuW - memW - portsW - 6 // Remaining space, -6 for separators
+	w := p.columnWidths()
+	nameW, statusW, cpuW, memW, pidsW := w.name, w.status, w.cpu, w.mem, w.pids
+	rateW, portsW, imageW := w.rate, w.ports, w.image
 
-	// Minimum widths
-	if nameW < 12 {
-		nameW = 12
+	// Header, with an arrow on the sort column. The STATUS column shows
+	// both state and uptime, so it is relabelled when sorting by uptime.
+	label := func(text, column string) string {
+		if p.sortBy != column {
+			return text
+		}
+		if p.sortDesc {
+			return text + "▼"
+		}
+		return text + "▲"
 	}
-	if statusW < 10 {
-		statusW = 10
+	statusLabel := label("STATUS", SortState)
+	if p.sortBy == SortUptime {
+		statusLabel = label("UPTIME", SortUptime)
 	}
-	if portsW < 10 {
-		portsW = 10
+	rateHeader := ""
+	if p.showNet {
+		rateHeader += fmt.Sprintf(" %*s", rateW, label("NET RX/TX", SortNet))
 	}
-	if imageW < 10 {
-		imageW = 10
+	if p.showIO {
+		rateHeader += fmt.Sprintf(" %*s", rateW, "IO R/W")
 	}
-
-	// Header
-	header := fmt.Sprintf("%-*s %-*s %*s %*s %-*s %-*s",
-		nameW, "NAME",
-		statusW, "STATUS",
-		cpuW, "CPU",
-		memW, "MEM",
+	header := fmt.Sprintf("%-*s %-*s %*s %*s %*s%s %-*s %-*s",
+		nameW, label("NAME", SortName),
+		statusW, statusLabel,
+		cpuW, label("CPU", SortCPU),
+		memW, label("MEM", SortMem),
+		pidsW, "PIDS",
+		rateHeader,
 		portsW, "PORTS",
-		imageW, "IMAGE",
+		imageW, label("IMAGE", SortImage),
 	)
+	if p.sortBy == SortNet && !p.showNet {
+		title += theme.InactiveStyle.Render(" [sort: " + label("net", SortNet) + "]")
+	}
 	headerStyled := theme.HighlightStyle.Render(header)
 
 	// Rows
@@ -159,9 +404,24 @@ func (p *ContainersPanel) View() string {
 		isSelected := i == p.selected
 
 		name := truncate(c.Name, nameW)
+		isMarked := p.marked[c.ID]
+		if isMarked {
+			name = truncate("● "+c.Name, nameW)
+		}
 		status := truncate(c.Status, statusW)
 		cpu := fmt.Sprintf("%5.1f%%", c.CPUPerc)
 		mem := fmt.Sprintf("%*s", memW, docker.FormatBytesShort(c.MemUsage))
+		pids := fmt.Sprintf("%*s", pidsW, "-")
+		if c.State == "running" {
+			pids = fmt.Sprintf("%*d", pidsW, c.PIDs)
+		}
+		rates := ""
+		if p.showNet {
+			rates += " " + formatRatePair(c.State, c.NetRxRate, c.NetTxRate, rateW)
+		}
+		if p.showIO {
+			rates += " " + formatRatePair(c.State, c.BlockReadRate, c.BlockWriteRate, rateW)
+		}
 		ports := truncate(c.Ports, portsW)
 		img := truncate(c.Image, imageW)
 
@@ -172,12 +432,14 @@ func (p *ContainersPanel) View() string {
 			if c.Autostart {
 				autostart = "A"
 			}
-			row = fmt.Sprintf("%s%-*s %-*s %*s %s %-*s %-*s",
+			row = fmt.Sprintf("%s%-*s %-*s %*s %s %s%s %-*s %-*s",
 				autostart,
 				nameW-1, name,
 				statusW, status,
 				cpuW, cpu,
 				mem,
+				pids,
+				rates,
 				portsW, ports,
 				imageW, img,
 			)
@@ -202,11 +464,15 @@ func (p *ContainersPanel) View() string {
 				autostart = theme.HighlightStyle.Render("A")
 			}
 
-			nameStyled := lipgloss.NewStyle().Width(nameW - 1).Render(name)
+			nameStyle := lipgloss.NewStyle()
+			if isMarked {
+				nameStyle = theme.MarkedStyle
+			}
+			nameStyled := nameStyle.Width(nameW - 1).Render(name)
 			portsStyled := lipgloss.NewStyle().Width(portsW).Render(ports)
 			imgStyled := lipgloss.NewStyle().Width(imageW).Render(img)
 
-			row = autostart + nameStyled + " " + statusStyled + " " + cpuStyled + " " + memStyled + " " + portsStyled + " " + imgStyled
+			row = autostart + nameStyled + " " + statusStyled + " " + cpuStyled + " " + memStyled + " " + pids + rates + " " + portsStyled + " " + imgStyled
 		}
 
 		rows = append(rows, row)
@@ -217,12 +483,135 @@ func (p *ContainersPanel) View() string {
 	return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n" + content)
 }
 
+// containerColumns holds the width of each table column
+type containerColumns struct {
+	name, status, cpu, mem, pids, rate, ports, image int
+}
+
+func (p *ContainersPanel) columnWidths() containerColumns {
+	// Calculate column widths - use proportional widths based on panel width
+	availableWidth := p.width - 10 // Account for borders and padding
+
+	nameW := availableWidth * 18 / 100   // 18%
+	statusW := availableWidth * 12 / 100 // 12%
+	cpuW := 8                             // Fixed width for CPU %
+	memW := 10                            // Fixed width for memory
+	pidsW := 5                            // Fixed width for PIDs
+	portsW := availableWidth * 22 / 100  // 22%
+	imageW := availableWidth - nameW - statusW - cpuW - memW - pidsW - portsW - 7 // Remaining space, -7 for separators
+
+	// Optional rate columns, shown as "rx/tx" and "read/write" per second
+	rateW := 11
+	rateCols := 0
+	if p.showNet {
+		rateCols++
+	}
+	if p.showIO {
+		rateCols++
+	}
+	imageW -= rateCols * (rateW + 1)
+
+	// Minimum widths
+	if nameW < 12 {
+		nameW = 12
+	}
+	if statusW < 10 {
+		statusW = 10
+	}
+	if portsW < 10 {
+		portsW = 10
+	}
+	if imageW < 10 {
+		imageW = 10
+	}
+
+	return containerColumns{
+		name:   nameW,
+		status: statusW,
+		cpu:    cpuW,
+		mem:    memW,
+		pids:   pidsW,
+		rate:   rateW,
+		ports:  portsW,
+		image:  imageW,
+	}
+}
+
+// SortAt sorts by the column whose header spans x, relative to the start of
+// the row. Clicking the current sort column reverses it. It reports whether
+// x fell on a sortable column.
+func (p *ContainersPanel) SortAt(x int) bool {
+	w := p.columnWidths()
+
+	type span struct {
+		width  int
+		column string
+	}
+	spans := []span{
+		{w.name, SortName},
+		{w.status, SortState},
+		{w.cpu, SortCPU},
+		{w.mem, SortMem},
+		{w.pids, ""},
+	}
+	if p.showNet {
+		spans = append(spans, span{w.rate, SortNet})
+	}
+	if p.showIO {
+		spans = append(spans, span{w.rate, ""})
+	}
+	spans = append(spans, span{w.ports, ""}, span{w.image, SortImage})
+
+	start := 0
+	for _, sp := range spans {
+		if x >= start && x < start+sp.width {
+			column := sp.column
+			if column == SortState && p.sortBy == SortUptime {
+				column = SortUptime
+			}
+			if column == "" {
+				return false
+			}
+			if column == p.sortBy {
+				p.ToggleSortOrder()
+			} else {
+				p.SetSort(column, false)
+			}
+			return true
+		}
+		start += sp.width + 1
+	}
+	return false
+}
+
+// SelectRow moves the cursor to the given visible row, counted from the
+// first row under the header
+func (p *ContainersPanel) SelectRow(row int) {
+	i := p.offset + row
+	if row < 0 || i >= len(p.GetFiltered()) {
+		return
+	}
+	p.selected = i
+	p.rememberSelection()
+}
+
+// formatRatePair renders two byte rates as "in/out", right-aligned
+func formatRatePair(state string, in, out float64, width int) string {
+	if state != "running" {
+		return fmt.Sprintf("%*s", width, "-")
+	}
+	pair := docker.FormatBytesShort(uint64(in)) + "/" + docker.FormatBytesShort(uint64(out))
+	return fmt.Sprintf("%*s", width, pair)
+}
+
+// truncate shortens s to maxLen terminal cells. It counts display width
+// rather than bytes, so it never cuts through a multibyte character.
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if ansi.StringWidth(s) <= maxLen {
 		return s
 	}
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return ansi.Truncate(s, maxLen, "")
 	}
-	return s[:maxLen-3] + "..."
+	return ansi.Truncate(s, maxLen, "...")
 }
@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateKeepsCharactersWhole(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"web", 10, "web"},
+		{"● frontend-api", 8, "● fro..."},
+		{"● データベース", 7, "● デ..."},
+		{"●name", 2, "●n"},
+	} {
+		got := truncate(tt.in, tt.max)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
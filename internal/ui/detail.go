@@ -0,0 +1,402 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// secretEnvMarkers are substrings of env var names whose values are masked
+var secretEnvMarkers = []string{
+	"PASSWORD", "PASSWD", "PASS", "SECRET", "TOKEN", "KEY", "CREDENTIAL", "AUTH", "PRIVATE",
+}
+
+const maskedValue = "********"
+
+type detailLine struct {
+	text    string
+	section bool
+	hot     bool // drawn in the warning colour, e.g. the largest layers
+}
+
+// DetailPanel shows an inspect result, of a container or of an image, as
+// sections of lines
+type DetailPanel struct {
+	width    int
+	height   int
+	kind     string // "Container" or "Image", shown in the title
+	name     string
+	lines    []detailLine
+	sections []int // line index of each section header
+	offset   int
+}
+
+func NewDetailPanel() *DetailPanel {
+	return &DetailPanel{kind: "Container"}
+}
+
+func (p *DetailPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.clampOffset()
+}
+
+// SetInspect renders an inspect result. Reloading the same container keeps
+// the scroll position.
+func (p *DetailPanel) SetInspect(info types.ContainerJSON) {
+	name := ""
+	if info.ContainerJSONBase != nil {
+		name = strings.TrimPrefix(info.Name, "/")
+	}
+	if name != p.name {
+		p.offset = 0
+	}
+	p.name = name
+	p.lines = nil
+	p.sections = nil
+
+	p.buildOverview(info)
+	p.buildCommand(info)
+	p.buildEnv(info)
+	p.buildMounts(info)
+	p.buildNetworks(info)
+	p.buildLabels(info)
+	p.buildState(info)
+
+	p.clampOffset()
+}
+
+func (p *DetailPanel) section(title string) {
+	if len(p.lines) > 0 {
+		p.lines = append(p.lines, detailLine{})
+	}
+	p.sections = append(p.sections, len(p.lines))
+	p.lines = append(p.lines, detailLine{text: title, section: true})
+}
+
+func (p *DetailPanel) field(label, value string) {
+	if value == "" {
+		value = "-"
+	}
+	p.lines = append(p.lines, detailLine{text: fmt.Sprintf("  %-16s %s", label+":", value)})
+}
+
+func (p *DetailPanel) item(text string) {
+	p.lines = append(p.lines, detailLine{text: "  " + text})
+}
+
+func (p *DetailPanel) none() {
+	p.lines = append(p.lines, detailLine{text: "  (none)"})
+}
+
+func (p *DetailPanel) buildOverview(info types.ContainerJSON) {
+	p.section("Overview")
+	if info.ContainerJSONBase == nil {
+		p.none()
+		return
+	}
+	p.field("Name", strings.TrimPrefix(info.Name, "/"))
+	p.field("ID", shortID(info.ID))
+	if info.Config != nil {
+		p.field("Image", info.Config.Image)
+		p.field("Hostname", info.Config.Hostname)
+	}
+	p.field("Created", formatTimestamp(info.Created))
+	p.field("Platform", info.Platform)
+}
+
+func (p *DetailPanel) buildCommand(info types.ContainerJSON) {
+	p.section("Command")
+	if info.Config == nil {
+		p.none()
+		return
+	}
+	p.field("Entrypoint", strings.Join(info.Config.Entrypoint, " "))
+	p.field("Command", strings.Join(info.Config.Cmd, " "))
+	p.field("Working dir", info.Config.WorkingDir)
+	p.field("User", info.Config.User)
+}
+
+func (p *DetailPanel) buildEnv(info types.ContainerJSON) {
+	p.section("Environment")
+	if info.Config == nil || len(info.Config.Env) == 0 {
+		p.none()
+		return
+	}
+	for _, kv := range info.Config.Env {
+		p.item(maskEnv(kv))
+	}
+}
+
+func (p *DetailPanel) buildMounts(info types.ContainerJSON) {
+	p.section("Mounts")
+	if len(info.Mounts) == 0 {
+		p.none()
+		return
+	}
+	for _, m := range info.Mounts {
+		source := m.Source
+		if m.Name != "" {
+			source = m.Name
+		}
+		mode := "rw"
+		if !m.RW {
+			mode = "ro"
+		}
+		p.item(fmt.Sprintf("%-7s %s -> %s (%s)", m.Type, source, m.Destination, mode))
+	}
+}
+
+func (p *DetailPanel) buildNetworks(info types.ContainerJSON) {
+	p.section("Networks")
+	if info.NetworkSettings == nil || len(info.NetworkSettings.Networks) == 0 {
+		p.none()
+	} else {
+		names := make([]string, 0, len(info.NetworkSettings.Networks))
+		for name := range info.NetworkSettings.Networks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			ep := info.NetworkSettings.Networks[name]
+			if ep == nil {
+				continue
+			}
+			addrs := []string{}
+			if ep.IPAddress != "" {
+				addrs = append(addrs, fmt.Sprintf("%s/%d", ep.IPAddress, ep.IPPrefixLen))
+			}
+			if ep.GlobalIPv6Address != "" {
+				addrs = append(addrs, ep.GlobalIPv6Address)
+			}
+			if len(addrs) == 0 {
+				addrs = append(addrs, "no address")
+			}
+			line := fmt.Sprintf("%s: %s", name, strings.Join(addrs, ", "))
+			if ep.Gateway != "" {
+				line += "  gw " + ep.Gateway
+			}
+			p.item(line)
+		}
+	}
+
+	if info.NetworkSettings != nil && len(info.NetworkSettings.Ports) > 0 {
+		var ports []string
+		for port, bindings := range info.NetworkSettings.Ports {
+			if len(bindings) == 0 {
+				ports = append(ports, string(port))
+				continue
+			}
+			for _, b := range bindings {
+				ports = append(ports, fmt.Sprintf("%s:%s->%s", b.HostIP, b.HostPort, port))
+			}
+		}
+		sort.Strings(ports)
+		p.field("Ports", strings.Join(ports, ", "))
+	}
+}
+
+func (p *DetailPanel) buildLabels(info types.ContainerJSON) {
+	p.section("Labels")
+	if info.Config == nil || len(info.Config.Labels) == 0 {
+		p.none()
+		return
+	}
+	keys := make([]string, 0, len(info.Config.Labels))
+	for k := range info.Config.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p.item(k + "=" + info.Config.Labels[k])
+	}
+}
+
+func (p *DetailPanel) buildState(info types.ContainerJSON) {
+	p.section("State")
+	if info.ContainerJSONBase == nil || info.State == nil {
+		p.none()
+		return
+	}
+
+	if info.HostConfig != nil {
+		policy := string(info.HostConfig.RestartPolicy.Name)
+		if policy == "" {
+			policy = "no"
+		}
+		if info.HostConfig.RestartPolicy.MaximumRetryCount > 0 {
+			policy += fmt.Sprintf(" (max %d retries)", info.HostConfig.RestartPolicy.MaximumRetryCount)
+		}
+		p.field("Restart policy", policy)
+	}
+
+	p.field("Status", info.State.Status)
+	health := "no healthcheck"
+	if info.State.Health != nil {
+		health = info.State.Health.Status
+		if info.State.Health.FailingStreak > 0 {
+			health += fmt.Sprintf(" (%d failing)", info.State.Health.FailingStreak)
+		}
+	}
+	p.field("Health", health)
+	p.field("Exit code", fmt.Sprintf("%d", info.State.ExitCode))
+	p.field("OOMKilled", fmt.Sprintf("%t", info.State.OOMKilled))
+	p.field("Restart count", fmt.Sprintf("%d", info.RestartCount))
+	p.field("Started", formatTimestamp(info.State.StartedAt))
+	if !info.State.Running {
+		p.field("Finished", formatTimestamp(info.State.FinishedAt))
+	}
+	if info.State.Error != "" {
+		p.field("Error", info.State.Error)
+	}
+
+	// Last healthcheck output helps explain an unhealthy status
+	if info.State.Health != nil && len(info.State.Health.Log) > 0 {
+		last := info.State.Health.Log[len(info.State.Health.Log)-1]
+		if last != nil {
+			output := strings.TrimSpace(strings.ReplaceAll(last.Output, "\n", " "))
+			p.field("Last check", fmt.Sprintf("exit %d: %s", last.ExitCode, output))
+		}
+	}
+}
+
+func (p *DetailPanel) visibleRows() int {
+	rows := p.height - 4
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+func (p *DetailPanel) clampOffset() {
+	maxOffset := len(p.lines) - p.visibleRows()
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+	if p.offset > maxOffset {
+		p.offset = maxOffset
+	}
+	if p.offset < 0 {
+		p.offset = 0
+	}
+}
+
+func (p *DetailPanel) ScrollUp() {
+	p.offset--
+	p.clampOffset()
+}
+
+func (p *DetailPanel) ScrollDown() {
+	p.offset++
+	p.clampOffset()
+}
+
+// NextSection scrolls to the next section header
+func (p *DetailPanel) NextSection() {
+	for _, idx := range p.sections {
+		if idx > p.offset {
+			p.offset = idx
+			break
+		}
+	}
+	p.clampOffset()
+}
+
+// PrevSection scrolls to the previous section header
+func (p *DetailPanel) PrevSection() {
+	for i := len(p.sections) - 1; i >= 0; i-- {
+		if p.sections[i] < p.offset {
+			p.offset = p.sections[i]
+			break
+		}
+	}
+	p.clampOffset()
+}
+
+func (p *DetailPanel) View() string {
+	style := theme.ActivePanelStyle
+
+	title := theme.TitleStyle.Render(" " + p.kind + " ")
+	if p.name != "" {
+		title += theme.InactiveStyle.Render(" [" + p.name + "]")
+	}
+
+	if len(p.lines) == 0 {
+		content := theme.InactiveStyle.Render("Loading...")
+		return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n\n" + content)
+	}
+
+	maxWidth := p.width - 6
+	var rows []string
+	for i := p.offset; i < len(p.lines) && i < p.offset+p.visibleRows(); i++ {
+		line := p.lines[i]
+		text := truncate(line.text, maxWidth)
+		if line.section {
+			text = theme.HighlightStyle.Render(text)
+		} else if line.hot {
+			text = theme.HighUsageStyle.Render(text)
+		}
+		rows = append(rows, text)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
+
+	return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n" + content)
+}
+
+// maskEnv hides the value of env vars that look like secrets, as well as
+// passwords embedded in URL values
+func maskEnv(kv string) string {
+	key, value, ok := strings.Cut(kv, "=")
+	if !ok || value == "" {
+		return kv
+	}
+
+	upper := strings.ToUpper(key)
+	for _, marker := range secretEnvMarkers {
+		if strings.Contains(upper, marker) {
+			return key + "=" + maskedValue
+		}
+	}
+
+	// Rewrite only the password so the rest of the URL stays as written
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			scheme := u.Scheme + "://"
+			rest := strings.TrimPrefix(value, scheme)
+			authority := rest
+			if end := strings.IndexAny(rest, "/?#"); end >= 0 {
+				authority = rest[:end]
+			}
+			if at := strings.LastIndex(authority, "@"); at >= 0 {
+				user, _, _ := strings.Cut(authority[:at], ":")
+				return key + "=" + scheme + user + ":" + maskedValue + rest[at:]
+			}
+		}
+	}
+
+	return kv
+}
+
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func formatTimestamp(ts string) string {
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil || t.IsZero() || t.Year() <= 1 {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
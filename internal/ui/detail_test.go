@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+func TestMaskEnv(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"PATH=/usr/bin", "PATH=/usr/bin"},
+		{"POSTGRES_PASSWORD=hunter2", "POSTGRES_PASSWORD=********"},
+		{"api_token=abc", "api_token=********"},
+		{"AWS_SECRET_ACCESS_KEY=xyz", "AWS_SECRET_ACCESS_KEY=********"},
+		{"DATABASE_URL=postgres://app:hunter2@db:5432/app", "DATABASE_URL=postgres://app:********@db:5432/app"},
+		{"REDIS_URL=redis://:p@ss@cache:6379/0", "REDIS_URL=redis://:********@cache:6379/0"},
+		{"UPSTREAM=http://user@proxy:3128", "UPSTREAM=http://user@proxy:3128"},
+		{"EMPTY_PASSWORD=", "EMPTY_PASSWORD="},
+		{"NO_VALUE", "NO_VALUE"},
+	}
+
+	for _, tt := range tests {
+		if got := maskEnv(tt.in); got != tt.want {
+			t.Errorf("maskEnv(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDetailPanelSections(t *testing.T) {
+	p := NewDetailPanel()
+	p.SetSize(120, 10)
+	p.SetInspect(types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:           "abc",
+			Name:         "/web",
+			RestartCount: 3,
+			State: &types.ContainerState{
+				Status:    "exited",
+				ExitCode:  137,
+				OOMKilled: true,
+				Health:    &types.Health{Status: "unhealthy", FailingStreak: 2},
+			},
+			HostConfig: &container.HostConfig{
+				RestartPolicy: container.RestartPolicy{Name: "on-failure", MaximumRetryCount: 5},
+			},
+		},
+		Config: &container.Config{
+			Entrypoint: []string{"/docker-entrypoint.sh"},
+			Cmd:        []string{"nginx", "-g", "daemon off;"},
+			Env:        []string{"API_KEY=secret"},
+			Labels:     map[string]string{"com.example.team": "web"},
+		},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"frontend": {IPAddress: "172.18.0.2", IPPrefixLen: 16},
+			},
+		},
+	})
+
+	var all []string
+	for _, l := range p.lines {
+		all = append(all, l.text)
+	}
+	text := strings.Join(all, "\n")
+	for _, want := range []string{
+		"/docker-entrypoint.sh",
+		"nginx -g daemon off;",
+		"API_KEY=********",
+		"frontend: 172.18.0.2/16",
+		"com.example.team=web",
+		"on-failure (max 5 retries)",
+		"unhealthy (2 failing)",
+		"137",
+		"true",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("detail view missing %q", want)
+		}
+	}
+	if strings.Contains(text, "secret") {
+		t.Error("secret value rendered")
+	}
+
+	p.NextSection()
+	if p.offset != p.sections[1] {
+		t.Errorf("offset = %d, want start of second section %d", p.offset, p.sections[1])
+	}
+	p.PrevSection()
+	if p.offset != 0 {
+		t.Errorf("offset = %d, want 0", p.offset)
+	}
+}
@@ -0,0 +1,322 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/docker/api/types/container"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// diffExpandDepth is how many directory levels are open initially
+const diffExpandDepth = 2
+
+// diffNode is a path in the changes tree. Directories the daemon didn't
+// report are created to hold their children and have no change of their own.
+type diffNode struct {
+	name     string
+	path     string
+	kind     container.ChangeType
+	changed  bool
+	children []*diffNode
+	expanded bool
+	files    int // changed files at or below this node
+}
+
+func (n *diffNode) child(name string) *diffNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &diffNode{name: name, path: strings.TrimSuffix(n.path, "/") + "/" + name}
+	n.children = append(n.children, c)
+	return c
+}
+
+// finish sorts the tree, directories first, and counts files
+func (n *diffNode) finish(depth int) {
+	sort.Slice(n.children, func(i, j int) bool {
+		a, b := n.children[i], n.children[j]
+		if (len(a.children) > 0) != (len(b.children) > 0) {
+			return len(a.children) > 0
+		}
+		return a.name < b.name
+	})
+	n.expanded = depth < diffExpandDepth
+	n.files = 0
+	if len(n.children) == 0 && n.changed {
+		n.files = 1
+	}
+	for _, c := range n.children {
+		c.finish(depth + 1)
+		n.files += c.files
+	}
+}
+
+// buildDiffTree arranges changed paths into a tree under "/"
+func buildDiffTree(changes []container.FilesystemChange) *diffNode {
+	root := &diffNode{path: "/"}
+	for _, ch := range changes {
+		node := root
+		for _, part := range strings.Split(strings.Trim(ch.Path, "/"), "/") {
+			if part != "" {
+				node = node.child(part)
+			}
+		}
+		node.kind = ch.Kind
+		node.changed = true
+	}
+	root.finish(0)
+	return root
+}
+
+type diffRow struct {
+	node  *diffNode
+	depth int
+}
+
+// DiffPanel shows the files a container added, changed and deleted
+type DiffPanel struct {
+	width  int
+	height int
+	name   string
+
+	root    *diffNode
+	rows    []diffRow
+	sizeRw  int64
+	counts  map[container.ChangeType]int
+	loaded  bool
+	cursor  int
+	offset  int
+	selPath string
+}
+
+func NewDiffPanel() *DiffPanel {
+	return &DiffPanel{}
+}
+
+func (p *DiffPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.scrollToCursor()
+}
+
+// SetContainer clears the tree for a newly shown container
+func (p *DiffPanel) SetContainer(name string) {
+	p.name = name
+	p.root = nil
+	p.rows = nil
+	p.loaded = false
+	p.cursor = 0
+	p.offset = 0
+	p.selPath = ""
+}
+
+// SetDiff shows a container's changes. Reloading keeps directories that
+// were opened or closed by hand, and the cursor on the same path.
+func (p *DiffPanel) SetDiff(diff docker.FilesystemDiff) {
+	expanded := map[string]bool{}
+	if p.root != nil {
+		p.walk(p.root, func(n *diffNode) { expanded[n.path] = n.expanded })
+	}
+
+	p.root = buildDiffTree(diff.Changes)
+	p.walk(p.root, func(n *diffNode) {
+		if open, ok := expanded[n.path]; ok {
+			n.expanded = open
+		}
+	})
+	p.root.expanded = true
+
+	p.sizeRw = diff.SizeRw
+	p.counts = map[container.ChangeType]int{}
+	for _, ch := range diff.Changes {
+		p.counts[ch.Kind]++
+	}
+	p.loaded = true
+	p.rebuildRows()
+}
+
+func (p *DiffPanel) walk(n *diffNode, fn func(*diffNode)) {
+	fn(n)
+	for _, c := range n.children {
+		p.walk(c, fn)
+	}
+}
+
+// rebuildRows lists the visible nodes after the tree was opened or closed
+func (p *DiffPanel) rebuildRows() {
+	p.rows = p.rows[:0]
+	var add func(n *diffNode, depth int)
+	add = func(n *diffNode, depth int) {
+		for _, c := range n.children {
+			p.rows = append(p.rows, diffRow{node: c, depth: depth})
+			if c.expanded {
+				add(c, depth+1)
+			}
+		}
+	}
+	add(p.root, 0)
+
+	p.cursor = 0
+	for i, row := range p.rows {
+		if row.node.path == p.selPath {
+			p.cursor = i
+			break
+		}
+	}
+	p.scrollToCursor()
+}
+
+func (p *DiffPanel) selected() *diffNode {
+	if p.cursor >= 0 && p.cursor < len(p.rows) {
+		return p.rows[p.cursor].node
+	}
+	return nil
+}
+
+func (p *DiffPanel) moveTo(i int) {
+	if i < 0 || i >= len(p.rows) {
+		return
+	}
+	p.cursor = i
+	p.selPath = p.rows[i].node.path
+	p.scrollToCursor()
+}
+
+func (p *DiffPanel) MoveUp()   { p.moveTo(p.cursor - 1) }
+func (p *DiffPanel) MoveDown() { p.moveTo(p.cursor + 1) }
+
+// SelectRow selects the node on a visible row, counted from the first row
+// below the header
+func (p *DiffPanel) SelectRow(row int) {
+	if row >= 0 && row < p.visibleRows() {
+		p.moveTo(p.offset + row)
+	}
+}
+
+// Toggle opens or closes the selected directory
+func (p *DiffPanel) Toggle() {
+	if n := p.selected(); n != nil && len(n.children) > 0 {
+		n.expanded = !n.expanded
+		p.rebuildRows()
+	}
+}
+
+// Expand opens the selected directory
+func (p *DiffPanel) Expand() {
+	if n := p.selected(); n != nil && len(n.children) > 0 && !n.expanded {
+		n.expanded = true
+		p.rebuildRows()
+	}
+}
+
+// Collapse closes the selected directory, or moves to the parent of a file
+// or closed directory
+func (p *DiffPanel) Collapse() {
+	n := p.selected()
+	if n == nil {
+		return
+	}
+	if len(n.children) > 0 && n.expanded {
+		n.expanded = false
+		p.rebuildRows()
+		return
+	}
+	depth := p.rows[p.cursor].depth
+	for i := p.cursor - 1; i >= 0; i-- {
+		if p.rows[i].depth < depth {
+			p.moveTo(i)
+			return
+		}
+	}
+}
+
+func (p *DiffPanel) visibleRows() int {
+	return max(p.height-5, 1)
+}
+
+func (p *DiffPanel) scrollToCursor() {
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	} else if p.cursor >= p.offset+p.visibleRows() {
+		p.offset = p.cursor - p.visibleRows() + 1
+	}
+	if maxOffset := max(len(p.rows)-p.visibleRows(), 0); p.offset > maxOffset {
+		p.offset = maxOffset
+	}
+}
+
+func (p *DiffPanel) View() string {
+	style := theme.ActivePanelStyle
+
+	title := theme.TitleStyle.Render(" Changes ")
+	if p.name != "" {
+		title += theme.InactiveStyle.Render(" [" + p.name + "]")
+	}
+
+	if !p.loaded || len(p.rows) == 0 {
+		msg := "Loading..."
+		if p.loaded {
+			msg = "No changes to the image filesystem"
+		}
+		content := theme.InactiveStyle.Render(msg)
+		return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n\n" + content)
+	}
+
+	summary := fmt.Sprintf("%s added  %s changed  %s deleted  ~%s written",
+		theme.RunningStyle.Render(fmt.Sprint(p.counts[container.ChangeAdd])),
+		theme.PausedStyle.Render(fmt.Sprint(p.counts[container.ChangeModify])),
+		theme.StoppedStyle.Render(fmt.Sprint(p.counts[container.ChangeDelete])),
+		docker.FormatBytes(uint64(max(p.sizeRw, 0))))
+
+	maxWidth := p.width - 6
+	rows := []string{summary, ""}
+	for i := p.offset; i < len(p.rows) && i < p.offset+p.visibleRows(); i++ {
+		row := p.rows[i]
+		n := row.node
+
+		marker := "  "
+		name := n.name
+		if len(n.children) > 0 {
+			marker = "▸ "
+			if n.expanded {
+				marker = "▾ "
+			}
+			files := fmt.Sprintf("%d files", n.files)
+			if n.files == 1 {
+				files = "1 file"
+			}
+			name += "/  (" + files + ")"
+		}
+
+		kind := " "
+		kindStyle := theme.InactiveStyle
+		if n.changed {
+			kind = n.kind.String()
+			switch n.kind {
+			case container.ChangeAdd:
+				kindStyle = theme.RunningStyle
+			case container.ChangeModify:
+				kindStyle = theme.PausedStyle
+			case container.ChangeDelete:
+				kindStyle = theme.StoppedStyle
+			}
+		}
+
+		indent := strings.Repeat("  ", row.depth)
+		if i == p.cursor {
+			text := truncate(kind+" "+indent+marker+name, maxWidth)
+			rows = append(rows, theme.SelectedStyle.Width(p.width-4).Render(text))
+			continue
+		}
+		rows = append(rows, kindStyle.Render(kind)+" "+truncate(indent+marker+name, maxWidth-2))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n" + content)
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/docker/docker/api/types/container"
+	"github.com/seb07-cloud/dktop/internal/docker"
+)
+
+func testDiff() docker.FilesystemDiff {
+	return docker.FilesystemDiff{
+		SizeRw: 3 << 20,
+		Changes: []container.FilesystemChange{
+			{Kind: container.ChangeModify, Path: "/var"},
+			{Kind: container.ChangeModify, Path: "/var/log"},
+			{Kind: container.ChangeAdd, Path: "/var/log/app"},
+			{Kind: container.ChangeAdd, Path: "/var/log/app/app.log"},
+			{Kind: container.ChangeAdd, Path: "/var/log/app/app.log.1"},
+			{Kind: container.ChangeModify, Path: "/etc"},
+			{Kind: container.ChangeDelete, Path: "/etc/motd"},
+			{Kind: container.ChangeAdd, Path: "/tmp.txt"},
+		},
+	}
+}
+
+func TestDiffTree(t *testing.T) {
+	root := buildDiffTree(testDiff().Changes)
+
+	if root.files != 4 {
+		t.Errorf("root files = %d, want 4", root.files)
+	}
+	var names []string
+	for _, c := range root.children {
+		names = append(names, c.name)
+	}
+	if got := strings.Join(names, " "); got != "etc var tmp.txt" {
+		t.Errorf("children = %q, want directories first, by name", got)
+	}
+	logDir := root.children[1].children[0]
+	if logDir.path != "/var/log" || logDir.files != 2 {
+		t.Errorf("/var/log = %q with %d files, want 2 files below it", logDir.path, logDir.files)
+	}
+	if !root.children[1].expanded || logDir.expanded {
+		t.Error("only the first levels should start expanded")
+	}
+}
+
+func TestDiffPanelToggleSurvivesReload(t *testing.T) {
+	p := NewDiffPanel()
+	p.SetSize(100, 30)
+	p.SetContainer("web")
+	p.SetDiff(testDiff())
+
+	view := ansi.Strip(p.View())
+	for _, want := range []string{"4 added", "3 changed", "1 deleted", "3.0MB", "var/  (2 files)", "log/  (2 files)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "app.log") {
+		t.Errorf("/var/log/app should start collapsed:\n%s", view)
+	}
+
+	// etc, motd, var, log
+	for i := 0; i < 3; i++ {
+		p.MoveDown()
+	}
+	p.Expand()
+	p.MoveDown() // app
+	p.Expand()
+	if view := ansi.Strip(p.View()); !strings.Contains(view, "app.log.1") {
+		t.Fatalf("expanded tree should list the log files:\n%s", view)
+	}
+
+	p.SetDiff(testDiff())
+	if view := ansi.Strip(p.View()); !strings.Contains(view, "app.log.1") {
+		t.Errorf("reload should keep directories open:\n%s", view)
+	}
+	if n := p.selected(); n == nil || n.path != "/var/log/app" {
+		t.Errorf("cursor should stay on /var/log/app after reload")
+	}
+
+	p.Collapse()
+	p.Collapse() // now closed: moves to the parent
+	if n := p.selected(); n == nil || n.path != "/var/log" {
+		t.Errorf("selected = %v, want /var/log", n)
+	}
+}
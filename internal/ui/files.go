@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// FilesPanel browses the filesystem of a single container, one directory
+// at a time, and shows the progress of a copy in or out of it
+type FilesPanel struct {
+	width  int
+	height int
+	name   string
+
+	dir          string
+	entries      []docker.FileEntry
+	loaded       bool
+	selected     int
+	selectedName string
+	offset       int
+
+	// Copy in progress; total is -1 if unknown
+	transferLabel string
+	transferDone  int64
+	transferTotal int64
+}
+
+func NewFilesPanel() *FilesPanel {
+	return &FilesPanel{dir: "/"}
+}
+
+func (p *FilesPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.scrollToSelected()
+}
+
+// SetContainer starts browsing a newly shown container at its root
+func (p *FilesPanel) SetContainer(name string) {
+	p.name = name
+	p.SetDir("/")
+}
+
+// SetDir clears the list while dir is loaded. Going up a level keeps the
+// cursor on the directory that was left.
+func (p *FilesPanel) SetDir(dir string) {
+	p.selectedName = ""
+	if path.Dir(p.dir) == dir && p.dir != dir {
+		p.selectedName = path.Base(p.dir)
+	}
+	p.dir = dir
+	p.entries = nil
+	p.loaded = false
+	p.selected = 0
+	p.offset = 0
+}
+
+// Dir is the directory being shown
+func (p *FilesPanel) Dir() string {
+	return p.dir
+}
+
+// SetEntries shows the contents of the current directory, keeping the
+// cursor on the same name across reloads
+func (p *FilesPanel) SetEntries(entries []docker.FileEntry) {
+	p.entries = entries
+	p.loaded = true
+	p.selected = 0
+	for i, e := range p.entries {
+		if e.Name == p.selectedName {
+			p.selected = i
+			break
+		}
+	}
+	p.rememberSelection()
+	p.scrollToSelected()
+}
+
+func (p *FilesPanel) MoveUp() {
+	if p.selected > 0 {
+		p.selected--
+		p.rememberSelection()
+		p.scrollToSelected()
+	}
+}
+
+func (p *FilesPanel) MoveDown() {
+	if p.selected < len(p.entries)-1 {
+		p.selected++
+		p.rememberSelection()
+		p.scrollToSelected()
+	}
+}
+
+// SelectRow selects the entry on a visible row, counted from the first
+// row below the header
+func (p *FilesPanel) SelectRow(row int) {
+	if i := p.offset + row; row >= 0 && i < len(p.entries) && row < p.visibleRows() {
+		p.selected = i
+		p.rememberSelection()
+	}
+}
+
+func (p *FilesPanel) GetSelected() *docker.FileEntry {
+	if p.selected >= 0 && p.selected < len(p.entries) {
+		return &p.entries[p.selected]
+	}
+	return nil
+}
+
+// SelectedPath is the full path of the selected entry in the container
+func (p *FilesPanel) SelectedPath() string {
+	if e := p.GetSelected(); e != nil {
+		return path.Join(p.dir, e.Name)
+	}
+	return ""
+}
+
+func (p *FilesPanel) rememberSelection() {
+	if e := p.GetSelected(); e != nil {
+		p.selectedName = e.Name
+	}
+}
+
+// SetTransfer shows a copy in progress
+func (p *FilesPanel) SetTransfer(label string, done, total int64) {
+	p.transferLabel = label
+	p.transferDone = done
+	p.transferTotal = total
+}
+
+// ClearTransfer hides the progress of a finished copy
+func (p *FilesPanel) ClearTransfer() {
+	p.transferLabel = ""
+}
+
+func (p *FilesPanel) visibleRows() int {
+	return max(p.height-5, 1)
+}
+
+func (p *FilesPanel) scrollToSelected() {
+	if p.selected < p.offset {
+		p.offset = p.selected
+	} else if p.selected >= p.offset+p.visibleRows() {
+		p.offset = p.selected - p.visibleRows() + 1
+	}
+}
+
+// transferLine describes the copy in progress, with a bar if its size is
+// known
+func (p *FilesPanel) transferLine() string {
+	if p.transferLabel == "" {
+		return ""
+	}
+	label := theme.HighlightStyle.Render(p.transferLabel) + "  "
+	done := docker.FormatBytes(uint64(p.transferDone))
+	if p.transferTotal <= 0 {
+		return label + done + theme.InactiveStyle.Render("  (Esc to cancel)")
+	}
+	percent := float64(p.transferDone) / float64(p.transferTotal) * 100
+	return label + theme.RenderProgressBar(percent, 30) +
+		fmt.Sprintf(" %3.0f%%  %s / %s", percent, done, docker.FormatBytes(uint64(p.transferTotal))) +
+		theme.InactiveStyle.Render("  (Esc to cancel)")
+}
+
+func (p *FilesPanel) View() string {
+	style := theme.ActivePanelStyle
+
+	title := theme.TitleStyle.Render(" Files ")
+	if p.name != "" {
+		title += theme.InactiveStyle.Render(" [" + p.name + "]")
+	}
+	title += " " + theme.HighlightStyle.Render(p.dir)
+
+	if len(p.entries) == 0 {
+		msg := "Loading..."
+		if p.loaded {
+			msg = "Empty directory"
+		}
+		content := theme.InactiveStyle.Render(msg)
+		if line := p.transferLine(); line != "" {
+			content = line + "\n\n" + content
+		}
+		return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n\n" + content)
+	}
+
+	const sizeW, modeW, timeW = 9, 10, 16
+	nameW := max(p.width-6-sizeW-modeW-timeW-3, 10)
+
+	header := fmt.Sprintf("%-*s %*s %-*s %s", nameW, "NAME", sizeW, "SIZE", modeW, "MODE", "MODIFIED")
+	rows := []string{theme.HighlightStyle.Render(header), p.transferLine()}
+	for i := p.offset; i < len(p.entries) && i < p.offset+p.visibleRows(); i++ {
+		e := p.entries[i]
+
+		name := e.Name
+		if e.IsDir() {
+			name += "/"
+		}
+		if e.LinkTarget != "" {
+			name += " -> " + e.LinkTarget
+		}
+		size := ""
+		if e.Size >= 0 {
+			size = docker.FormatBytesShort(uint64(e.Size))
+		}
+		// Listings from ls carry only the names
+		mode, modified := "", ""
+		if !e.ModTime.IsZero() {
+			mode = e.Mode.String()
+			modified = e.ModTime.Local().Format("2006-01-02 15:04")
+		}
+
+		row := fmt.Sprintf("%-*s %*s %-*s %s", nameW, truncate(name, nameW), sizeW, size, modeW, mode, modified)
+		switch {
+		case i == p.selected:
+			rows = append(rows, theme.SelectedStyle.Width(p.width-4).Render(row))
+		case e.IsDir():
+			rows = append(rows, theme.HighlightStyle.Render(row))
+		default:
+			rows = append(rows, strings.TrimRight(row, " "))
+		}
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n" + content)
+}
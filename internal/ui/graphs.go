@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// GraphsPanel draws the resource history of a single container
+type GraphsPanel struct {
+	width   int
+	height  int
+	name    string
+	samples []statsSample
+}
+
+func NewGraphsPanel() *GraphsPanel {
+	return &GraphsPanel{}
+}
+
+func (p *GraphsPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetContainer sets the container whose history is shown
+func (p *GraphsPanel) SetContainer(name string, samples []statsSample) {
+	p.name = name
+	p.samples = samples
+}
+
+// SetSamples replaces the history of the current container
+func (p *GraphsPanel) SetSamples(samples []statsSample) {
+	p.samples = samples
+}
+
+func (p *GraphsPanel) View() string {
+	style := theme.ActivePanelStyle
+
+	title := theme.TitleStyle.Render(" History ")
+	if p.name != "" {
+		title += theme.InactiveStyle.Render(" [" + p.name + "]")
+	}
+	if len(p.samples) > 1 {
+		span := p.samples[len(p.samples)-1].At.Sub(p.samples[0].At).Round(time.Second)
+		title += theme.InactiveStyle.Render(fmt.Sprintf(" last %s", span))
+	}
+
+	if len(p.samples) == 0 {
+		content := theme.InactiveStyle.Render("No samples yet (container not running?)")
+		return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n\n" + content)
+	}
+
+	// Two columns of three graphs: each graph is a header line plus the
+	// graph itself, with a blank line between rows
+	colWidth := (p.width - 6) / 2
+	if colWidth < 10 {
+		colWidth = 10
+	}
+	graphHeight := (p.height-3-2)/3 - 1
+	if graphHeight < 1 {
+		graphHeight = 1
+	}
+
+	series := func(get func(statsSample) float64) []float64 {
+		data := make([]float64, len(p.samples))
+		for i, s := range p.samples {
+			data[i] = get(s)
+		}
+		return data
+	}
+
+	last := p.samples[len(p.samples)-1]
+	cpu := series(func(s statsSample) float64 { return s.CPUPerc })
+	mem := series(func(s statsSample) float64 { return s.MemPerc })
+
+	left := lipgloss.JoinVertical(lipgloss.Left,
+		p.percentGraph("CPU", fmt.Sprintf("%.1f%%", last.CPUPerc), cpu, colWidth, graphHeight, theme.Cyan),
+		"",
+		p.rateGraph("NET RX", series(func(s statsSample) float64 { return s.NetRxRate }), colWidth, graphHeight, theme.Green),
+		"",
+		p.rateGraph("IO READ", series(func(s statsSample) float64 { return s.BlockReadRate }), colWidth, graphHeight, theme.Blue),
+	)
+	right := lipgloss.JoinVertical(lipgloss.Left,
+		p.percentGraph("MEM", fmt.Sprintf("%s (%.1f%%)", docker.FormatBytes(last.MemUsage), last.MemPerc), mem, colWidth, graphHeight, theme.Purple),
+		"",
+		p.rateGraph("NET TX", series(func(s statsSample) float64 { return s.NetTxRate }), colWidth, graphHeight, theme.Orange),
+		"",
+		p.rateGraph("IO WRITE", series(func(s statsSample) float64 { return s.BlockWriteRate }), colWidth, graphHeight, theme.Pink),
+	)
+
+	content := lipgloss.JoinHorizontal(lipgloss.Top, left, "  ", right)
+
+	return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n" + content)
+}
+
+// percentGraph draws a 0-100% series
+func (p *GraphsPanel) percentGraph(label, current string, data []float64, width, height int, color lipgloss.Color) string {
+	peak := 0.0
+	for _, v := range data {
+		if v > peak {
+			peak = v
+		}
+	}
+	header := graphHeader(label, current, fmt.Sprintf("peak %.1f%%", peak), color)
+	return header + "\n" + theme.RenderLineGraph(data, width, height, color)
+}
+
+// rateGraph draws a bytes/s series scaled to its own peak
+func (p *GraphsPanel) rateGraph(label string, data []float64, width, height int, color lipgloss.Color) string {
+	peak := 0.0
+	for _, v := range data {
+		if v > peak {
+			peak = v
+		}
+	}
+
+	scaled := make([]float64, len(data))
+	if peak > 0 {
+		for i, v := range data {
+			scaled[i] = v / peak * 100
+		}
+	}
+
+	current := data[len(data)-1]
+	header := graphHeader(label, formatRate(current), "peak "+formatRate(peak), color)
+	return header + "\n" + theme.RenderLineGraph(scaled, width, height, color)
+}
+
+func graphHeader(label, current, peak string, color lipgloss.Color) string {
+	labelStyle := lipgloss.NewStyle().Foreground(color).Bold(true)
+	return labelStyle.Render(label+":") + " " + current + " " + theme.InactiveStyle.Render(peak)
+}
+
+func formatRate(bytesPerSec float64) string {
+	return docker.FormatBytes(uint64(bytesPerSec)) + "/s"
+}
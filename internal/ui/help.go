@@ -44,8 +44,18 @@ func (h *HelpBar) View(panel Panel) string {
 			{"s", "start"},
 			{"x", "stop"},
 			{"r", "restart"},
+			{"p", "pause"},
+			{"K", "kill"},
+			{"e", "shell"},
+			{"t", "processes"},
+			{"c", "changes"},
+			{"f", "files"},
 			{"d", "delete"},
 			{"a", "autostart"},
+			{"Space", "mark"},
+			{"i", "inspect"},
+			{"g", "graphs"},
+			{"</>", "sort"},
 			{"Enter", "logs"},
 		}
 	case PanelImages:
@@ -54,6 +64,14 @@ func (h *HelpBar) View(panel Panel) string {
 			desc string
 		}{
 			{"p", "pull"},
+			{"u", "push"},
+			{"P", "pull queue"},
+			{"L", "login"},
+			{"i", "history"},
+			{"r", "run"},
+			{"t", "tag"},
+			{"x", "untag"},
+			{"Space", "tags"},
 			{"d", "delete"},
 		}
 	case PanelLogs:
@@ -63,6 +81,79 @@ func (h *HelpBar) View(panel Panel) string {
 		}{
 			{"j/k", "scroll"},
 			{"G", "bottom"},
+			{"o", "stdout/stderr"},
+			{"Esc", "back"},
+		}
+	case PanelDetail:
+		keys = []struct {
+			key  string
+			desc string
+		}{
+			{"j/k", "scroll"},
+			{"n/p", "section"},
+			{"r", "reload"},
+			{"Esc", "back"},
+		}
+	case PanelImageInfo:
+		keys = []struct {
+			key  string
+			desc string
+		}{
+			{"j/k", "scroll"},
+			{"n/p", "section"},
+			{"r", "reload"},
+			{"Esc", "back"},
+		}
+	case PanelProcesses:
+		keys = []struct {
+			key  string
+			desc string
+		}{
+			{"j/k", "select"},
+			{"K", "signal"},
+			{"r", "reload"},
+			{"Esc", "back"},
+		}
+	case PanelDiff:
+		keys = []struct {
+			key  string
+			desc string
+		}{
+			{"j/k", "select"},
+			{"Enter", "open/close"},
+			{"h/l", "collapse/expand"},
+			{"r", "reload"},
+			{"Esc", "back"},
+		}
+	case PanelFiles:
+		keys = []struct {
+			key  string
+			desc string
+		}{
+			{"j/k", "select"},
+			{"Enter/l", "open"},
+			{"h", "up"},
+			{"d", "download"},
+			{"u", "upload"},
+			{"r", "reload"},
+			{"Esc", "cancel/back"},
+		}
+	case PanelPull:
+		keys = []struct {
+			key  string
+			desc string
+		}{
+			{"j/k", "select"},
+			{"x", "cancel"},
+			{"r/R", "retry/all failed"},
+			{"c", "clear done"},
+			{"Esc", "back"},
+		}
+	case PanelGraphs:
+		keys = []struct {
+			key  string
+			desc string
+		}{
 			{"Esc", "back"},
 		}
 	default:
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/seb07-cloud/dktop/internal/docker"
+)
+
+// statsSample is one point of a container's resource history
+type statsSample struct {
+	At             time.Time
+	CPUPerc        float64
+	MemUsage       uint64
+	MemPerc        float64
+	NetRxRate      float64
+	NetTxRate      float64
+	BlockReadRate  float64
+	BlockWriteRate float64
+}
+
+type containerHistory struct {
+	samples *ring[statsSample]
+	lastAt  time.Time
+}
+
+// History keeps a bounded window of stats samples for every container
+type History struct {
+	size       int
+	containers map[string]*containerHistory
+}
+
+func NewHistory(size int) *History {
+	if size < 2 {
+		size = 2
+	}
+	return &History{
+		size:       size,
+		containers: make(map[string]*containerHistory),
+	}
+}
+
+// Record adds a stats sample as published by the stats collector
+func (h *History) Record(info docker.ContainerInfo) {
+	if info.Sampled.IsZero() {
+		info.Sampled = time.Now()
+	}
+
+	ch, ok := h.containers[info.ID]
+	if !ok {
+		ch = &containerHistory{samples: newRing[statsSample](h.size)}
+		h.containers[info.ID] = ch
+	} else if !info.Sampled.After(ch.lastAt) {
+		// Duplicate or out-of-order sample
+		return
+	}
+	ch.lastAt = info.Sampled
+
+	ch.samples.Push(statsSample{
+		At:             info.Sampled,
+		CPUPerc:        info.CPUPerc,
+		MemUsage:       info.MemUsage,
+		MemPerc:        info.MemPerc,
+		NetRxRate:      info.NetRxRate,
+		NetTxRate:      info.NetTxRate,
+		BlockReadRate:  info.BlockReadRate,
+		BlockWriteRate: info.BlockWriteRate,
+	})
+}
+
+// Samples returns a container's history, oldest first
+func (h *History) Samples(containerID string) []statsSample {
+	ch, ok := h.containers[containerID]
+	if !ok {
+		return nil
+	}
+	return ch.samples.Slice()
+}
+
+// Forget drops the history of a removed container
+func (h *History) Forget(containerID string) {
+	delete(h.containers, containerID)
+}
+
+// Retain drops the history of every container not in ids
+func (h *History) Retain(ids []string) {
+	keep := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		keep[id] = true
+	}
+	for id := range h.containers {
+		if !keep[id] {
+			delete(h.containers, id)
+		}
+	}
+}
+
+// Reset drops all history, e.g. after switching hosts
+func (h *History) Reset() {
+	h.containers = make(map[string]*containerHistory)
+}
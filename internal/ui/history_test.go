@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/seb07-cloud/dktop/internal/docker"
+)
+
+func TestHistoryKeepsCollectorRates(t *testing.T) {
+	h := NewHistory(10)
+	h.Record(docker.ContainerInfo{
+		ID:             "web1",
+		NetRxRate:      1000,
+		NetTxRate:      500,
+		BlockReadRate:  4096,
+		BlockWriteRate: 2048,
+		Sampled:        time.Now(),
+	})
+
+	samples := h.Samples("web1")
+	if len(samples) != 1 {
+		t.Fatalf("samples = %d, want 1", len(samples))
+	}
+	s := samples[0]
+	if s.NetRxRate != 1000 || s.NetTxRate != 500 || s.BlockReadRate != 4096 || s.BlockWriteRate != 2048 {
+		t.Errorf("sample = %+v, want the collector's rates as recorded", s)
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	h := NewHistory(3)
+	t0 := time.Now()
+	for i := 0; i < 5; i++ {
+		h.Record(docker.ContainerInfo{ID: "web1", CPUPerc: float64(i), Sampled: t0.Add(time.Duration(i) * time.Second)})
+	}
+	// A sample that isn't newer than the last one is ignored
+	h.Record(docker.ContainerInfo{ID: "web1", CPUPerc: 99, Sampled: t0})
+
+	samples := h.Samples("web1")
+	if len(samples) != 3 || samples[0].CPUPerc != 2 || samples[2].CPUPerc != 4 {
+		t.Fatalf("samples = %+v, want the last 3", samples)
+	}
+
+	h.Record(docker.ContainerInfo{ID: "db1", Sampled: t0})
+	h.Retain([]string{"db1"})
+	if h.Samples("web1") != nil || h.Samples("db1") == nil {
+		t.Fatal("Retain should keep only db1")
+	}
+}
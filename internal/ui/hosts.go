@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+type HostsPanel struct {
+	width    int
+	height   int
+	hosts    []docker.Host
+	current  string
+	selected int
+}
+
+func NewHostsPanel() *HostsPanel {
+	return &HostsPanel{}
+}
+
+func (p *HostsPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetHosts replaces the host list and selects the active host
+func (p *HostsPanel) SetHosts(hosts []docker.Host, current string) {
+	p.hosts = hosts
+	p.current = current
+	p.selected = 0
+	for i, h := range hosts {
+		if h.Name == current {
+			p.selected = i
+			break
+		}
+	}
+}
+
+func (p *HostsPanel) MoveUp() {
+	if p.selected > 0 {
+		p.selected--
+	}
+}
+
+func (p *HostsPanel) MoveDown() {
+	if p.selected < len(p.hosts)-1 {
+		p.selected++
+	}
+}
+
+func (p *HostsPanel) GetSelected() *docker.Host {
+	if p.selected >= 0 && p.selected < len(p.hosts) {
+		return &p.hosts[p.selected]
+	}
+	return nil
+}
+
+func (p *HostsPanel) View() string {
+	style := theme.ActivePanelStyle
+
+	title := theme.TitleStyle.Render(" Docker Hosts ") +
+		theme.InactiveStyle.Render(" Enter:connect Esc:cancel")
+
+	nameW := 20
+	sourceW := 8
+	endpointW := p.width - nameW - sourceW - 10
+	if endpointW < 20 {
+		endpointW = 20
+	}
+
+	header := fmt.Sprintf("  %-*s %-*s %-*s", nameW, "NAME", sourceW, "SOURCE", endpointW, "ENDPOINT")
+	headerStyled := theme.HighlightStyle.Render(header)
+
+	visibleRows := p.height - 5
+	if visibleRows < 1 {
+		visibleRows = 1
+	}
+	offset := 0
+	if p.selected >= visibleRows {
+		offset = p.selected - visibleRows + 1
+	}
+
+	var rows []string
+	for i := offset; i < len(p.hosts) && i < offset+visibleRows; i++ {
+		h := p.hosts[i]
+
+		marker := " "
+		if h.Name == p.current {
+			marker = "*"
+		}
+		row := fmt.Sprintf("%s %-*s %-*s %-*s",
+			marker,
+			nameW, truncate(h.Name, nameW),
+			sourceW, h.Source,
+			endpointW, truncate(h.Endpoint(), endpointW),
+		)
+
+		if i == p.selected {
+			row = theme.SelectedStyle.Width(p.width - 4).Render(row)
+		} else if h.Name == p.current {
+			row = theme.RunningStyle.Render(row)
+		}
+		rows = append(rows, row)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, append([]string{headerStyled, ""}, rows...)...)
+
+	return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n" + content)
+}
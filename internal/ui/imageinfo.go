@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/seb07-cloud/dktop/internal/docker"
+)
+
+// largestLayers is how many of an image's biggest layers are highlighted
+const largestLayers = 3
+
+func NewImageInfoPanel() *DetailPanel {
+	return &DetailPanel{kind: "Image"}
+}
+
+// SetImage renders an image's history and config; layer ages are relative
+// to now. Reloading the same image keeps the scroll position.
+func (p *DetailPanel) SetImage(d *docker.ImageDetail, now time.Time) {
+	name := shortID(d.ID)
+	if len(d.Tags) > 0 {
+		name = d.Tags[0]
+	}
+	if name != p.name {
+		p.offset = 0
+	}
+	p.name = name
+	p.lines = nil
+	p.sections = nil
+
+	p.buildImageOverview(d, now)
+	p.buildLayers(d, now)
+	p.buildImageConfig(d)
+
+	p.section("Environment")
+	if len(d.Env) == 0 {
+		p.none()
+	}
+	for _, kv := range d.Env {
+		p.item(maskEnv(kv))
+	}
+
+	p.section("Labels")
+	if len(d.Labels) == 0 {
+		p.none()
+	}
+	keys := make([]string, 0, len(d.Labels))
+	for k := range d.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p.item(k + "=" + d.Labels[k])
+	}
+
+	p.clampOffset()
+}
+
+func (p *DetailPanel) buildImageOverview(d *docker.ImageDetail, now time.Time) {
+	p.section("Overview")
+	p.field("Tags", strings.Join(d.Tags, ", "))
+	p.field("ID", shortID(d.ID))
+	for _, digest := range d.Digests {
+		p.field("Digest", digest)
+	}
+	created := "-"
+	if !d.Created.IsZero() {
+		created = d.Created.Local().Format("2006-01-02 15:04:05") + " (" + age(d.Created, now) + ")"
+	}
+	p.field("Created", created)
+	p.field("Size", docker.FormatBytes(uint64(d.Size)))
+	p.field("Author", d.Author)
+}
+
+// buildLayers lists the history newest first, like docker history, and
+// highlights the layers that add the most to the image
+func (p *DetailPanel) buildLayers(d *docker.ImageDetail, now time.Time) {
+	p.section("Layers")
+	if len(d.Layers) == 0 {
+		p.none()
+		return
+	}
+
+	var sizes []int64
+	var total int64
+	for _, l := range d.Layers {
+		if l.Size > 0 {
+			sizes = append(sizes, l.Size)
+			total += l.Size
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] > sizes[j] })
+	top := sizes[:min(largestLayers, len(sizes))]
+	var threshold, largest int64
+	for _, size := range top {
+		threshold = size
+		largest += size
+	}
+
+	summary := fmt.Sprintf("%d steps, %d adding files", len(d.Layers), len(sizes))
+	if total > 0 {
+		summary += fmt.Sprintf("; the largest %d make up %.0f%%", len(top), float64(largest)/float64(total)*100)
+	}
+	p.item(summary)
+	p.item(fmt.Sprintf("%-16s %9s  %s", "CREATED", "SIZE", "CREATED BY"))
+
+	for _, l := range d.Layers {
+		created := "-"
+		if l.Created.Unix() > 0 {
+			created = age(l.Created, now)
+		}
+		text := fmt.Sprintf("  %-16s %9s  %s", created, docker.FormatBytes(uint64(l.Size)), l.CreatedBy)
+		p.lines = append(p.lines, detailLine{text: text, hot: l.Size > 0 && l.Size >= threshold})
+	}
+}
+
+func (p *DetailPanel) buildImageConfig(d *docker.ImageDetail) {
+	p.section("Config")
+	p.field("Entrypoint", strings.Join(d.Entrypoint, " "))
+	p.field("Cmd", strings.Join(d.Cmd, " "))
+	p.field("Working dir", d.WorkingDir)
+	p.field("User", d.User)
+	p.field("Exposed ports", strings.Join(d.ExposedPorts, ", "))
+	arch := d.Architecture
+	if d.Variant != "" {
+		arch += "/" + d.Variant
+	}
+	p.field("Architecture", arch)
+	p.field("OS", d.OS)
+}
+
+// age says how long before now t was, e.g. "3 weeks ago"
+func age(t, now time.Time) string {
+	return units.HumanDuration(now.Sub(t)) + " ago"
+}
@@ -17,10 +17,19 @@ type ImagesPanel struct {
 	offset   int
 	active   bool
 	filter   string
+	expanded map[string]bool // IDs of images whose tags are all listed
+}
+
+// imageRow is a line of the list: an image under its first tag, or one of
+// the further tags of an expanded image
+type imageRow struct {
+	image docker.ImageInfo
+	tag   string // "" for an untagged image
+	extra bool
 }
 
 func NewImagesPanel() *ImagesPanel {
-	return &ImagesPanel{}
+	return &ImagesPanel{expanded: map[string]bool{}}
 }
 
 func (p *ImagesPanel) SetSize(width, height int) {
@@ -34,8 +43,17 @@ func (p *ImagesPanel) SetActive(active bool) {
 
 func (p *ImagesPanel) Update(images []docker.ImageInfo) {
 	p.images = images
-	if p.selected >= len(p.images) {
-		p.selected = len(p.images) - 1
+	present := map[string]bool{}
+	for _, img := range images {
+		present[img.ID] = true
+	}
+	for id := range p.expanded {
+		if !present[id] {
+			delete(p.expanded, id)
+		}
+	}
+	if rows := p.rows(); p.selected >= len(rows) {
+		p.selected = len(rows) - 1
 	}
 	if p.selected < 0 {
 		p.selected = 0
@@ -73,6 +91,60 @@ func (p *ImagesPanel) GetFiltered() []docker.ImageInfo {
 	return filtered
 }
 
+// rows lists the filtered images, with the further tags of expanded ones
+func (p *ImagesPanel) rows() []imageRow {
+	var rows []imageRow
+	for _, img := range p.GetFiltered() {
+		if len(img.Tags) == 0 {
+			rows = append(rows, imageRow{image: img})
+			continue
+		}
+		rows = append(rows, imageRow{image: img, tag: img.Tags[0]})
+		if p.expanded[img.ID] {
+			for _, tag := range img.Tags[1:] {
+				rows = append(rows, imageRow{image: img, tag: tag, extra: true})
+			}
+		}
+	}
+	return rows
+}
+
+// ToggleExpand lists all tags of the selected image, or folds them back
+// under its first tag
+func (p *ImagesPanel) ToggleExpand() {
+	if img := p.GetSelected(); img != nil && p.expanded[img.ID] {
+		p.Collapse()
+	} else {
+		p.Expand()
+	}
+}
+
+// Expand lists all tags of the selected image, one per row
+func (p *ImagesPanel) Expand() {
+	if img := p.GetSelected(); img != nil && len(img.Tags) > 1 {
+		p.expanded[img.ID] = true
+	}
+}
+
+// Collapse folds the tags of the selected image back under its first tag,
+// moving the cursor there
+func (p *ImagesPanel) Collapse() {
+	img := p.GetSelected()
+	if img == nil || !p.expanded[img.ID] {
+		return
+	}
+	delete(p.expanded, img.ID)
+	for i, row := range p.rows() {
+		if row.image.ID == img.ID {
+			p.selected = i
+			break
+		}
+	}
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+}
+
 func (p *ImagesPanel) MoveUp() {
 	if p.selected > 0 {
 		p.selected--
@@ -83,8 +155,7 @@ func (p *ImagesPanel) MoveUp() {
 }
 
 func (p *ImagesPanel) MoveDown() {
-	filtered := p.GetFiltered()
-	if p.selected < len(filtered)-1 {
+	if p.selected < len(p.rows())-1 {
 		p.selected++
 		visibleRows := p.height - 5
 		if p.selected >= p.offset+visibleRows {
@@ -93,14 +164,35 @@ func (p *ImagesPanel) MoveDown() {
 	}
 }
 
+// SelectRow moves the cursor to the given visible row, counted from the
+// first row under the header
+func (p *ImagesPanel) SelectRow(row int) {
+	i := p.offset + row
+	if row < 0 || i >= len(p.rows()) {
+		return
+	}
+	p.selected = i
+}
+
 func (p *ImagesPanel) GetSelected() *docker.ImageInfo {
-	filtered := p.GetFiltered()
-	if p.selected >= 0 && p.selected < len(filtered) {
-		return &filtered[p.selected]
+	rows := p.rows()
+	if p.selected >= 0 && p.selected < len(rows) {
+		return &rows[p.selected].image
 	}
 	return nil
 }
 
+// SelectedTag returns the tag on the selected row, which is the image's
+// first tag unless one of its further tags is selected. It is empty for
+// an untagged image.
+func (p *ImagesPanel) SelectedTag() string {
+	rows := p.rows()
+	if p.selected >= 0 && p.selected < len(rows) {
+		return rows[p.selected].tag
+	}
+	return ""
+}
+
 func (p *ImagesPanel) View() string {
 	style := theme.PanelStyle
 	if p.active {
@@ -112,9 +204,9 @@ func (p *ImagesPanel) View() string {
 		title += theme.InactiveStyle.Render(fmt.Sprintf(" [%s]", p.filter))
 	}
 
-	filtered := p.GetFiltered()
+	items := p.rows()
 
-	if len(filtered) == 0 {
+	if len(items) == 0 {
 		content := theme.InactiveStyle.Render("No images")
 		return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n\n" + content)
 	}
@@ -127,7 +219,7 @@ func (p *ImagesPanel) View() string {
 	sizeW := 10
 
 	// Header
-	header := fmt.Sprintf("%-*s %*s", tagW, "REPOSITORY:TAG", sizeW, "SIZE")
+	header := fmt.Sprintf("  %-*s %*s", tagW-2, "REPOSITORY:TAG", sizeW, "SIZE")
 	headerStyled := theme.HighlightStyle.Render(header)
 
 	// Rows
@@ -140,17 +232,29 @@ func (p *ImagesPanel) View() string {
 	textStyle := lipgloss.NewStyle()
 
 	var rows []string
-	for i := p.offset; i < len(filtered) && i < p.offset+visibleRows; i++ {
-		img := filtered[i]
+	for i := p.offset; i < len(items) && i < p.offset+visibleRows; i++ {
+		item := items[i]
+		img := item.image
 		isSelected := i == p.selected
 
-		tag := "<none>"
-		if len(img.Tags) > 0 {
-			tag = img.Tags[0]
+		// Images with several tags fold them under a marker; expanded,
+		// each further tag gets its own row
+		marker, tag, size := "  ", item.tag, ""
+		switch {
+		case item.extra:
+			marker = "  └ "
+		case len(img.Tags) > 1 && p.expanded[img.ID]:
+			marker = "▾ "
+		case len(img.Tags) > 1:
+			marker = "▸ "
+			tag = fmt.Sprintf("%s (+%d)", tag, len(img.Tags)-1)
+		case len(img.Tags) == 0:
+			tag = "<none>"
+		}
+		tag = marker + truncate(tag, tagW-lipgloss.Width(marker))
+		if !item.extra {
+			size = docker.FormatBytesShort(uint64(img.Size))
 		}
-		tag = truncate(tag, tagW)
-
-		size := docker.FormatBytesShort(uint64(img.Size))
 
 		row := fmt.Sprintf("%-*s %*s", tagW, tag, sizeW, size)
 
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// LoginForm collects registry credentials, like docker login
+type LoginForm struct {
+	server, username, password *runField
+
+	fields []*runField
+	focus  int
+	err    string
+
+	onLogin func(creds docker.Credentials) tea.Cmd
+}
+
+func NewLoginForm(server string, onLogin func(docker.Credentials) tea.Cmd) *LoginForm {
+	f := &LoginForm{
+		server:   newRunTextField("Registry", "docker.io"),
+		username: newRunTextField("Username", ""),
+		password: newRunTextField("Password", "password or access token"),
+		onLogin:  onLogin,
+	}
+	f.password.input.EchoMode = textinput.EchoPassword
+	f.password.input.EchoCharacter = '•'
+	f.server.input.SetValue(server)
+	f.fields = []*runField{f.server, f.username, f.password}
+	f.setFocus(1)
+	return f
+}
+
+func (f *LoginForm) setFocus(i int) {
+	f.focus = (i + len(f.fields)) % len(f.fields)
+	for j, field := range f.fields {
+		if j == f.focus {
+			field.input.Focus()
+		} else {
+			field.input.Blur()
+		}
+	}
+}
+
+// HandleKey processes the keys that drive the form; typing is left to
+// Update. It returns done once the form should close, along with the
+// login command if it was submitted.
+func (f *LoginForm) HandleKey(key string) (done bool, cmd tea.Cmd) {
+	switch key {
+	case "esc":
+		return true, nil
+	case "enter":
+		creds, err := f.credentials()
+		if err != nil {
+			f.err = err.Error()
+			return false, nil
+		}
+		return true, f.onLogin(creds)
+	case "tab", "down":
+		f.setFocus(f.focus + 1)
+	case "shift+tab", "up":
+		f.setFocus(f.focus - 1)
+	}
+	return false, nil
+}
+
+// Update passes a message on to the focused text input
+func (f *LoginForm) Update(msg tea.Msg) tea.Cmd {
+	field := f.fields[f.focus]
+	var cmd tea.Cmd
+	*field.input, cmd = field.input.Update(msg)
+	return cmd
+}
+
+func (f *LoginForm) credentials() (docker.Credentials, error) {
+	creds := docker.Credentials{
+		Server:   f.server.value(),
+		Username: f.username.value(),
+		Password: f.password.input.Value(),
+	}
+	if creds.Server == "" {
+		creds.Server = "docker.io"
+	}
+	if creds.Username == "" || creds.Password == "" {
+		return creds, errors.New("enter a username and a password")
+	}
+	return creds, nil
+}
+
+// registryName is how a registry is shown: Docker Hub by its domain
+// rather than the index URL its credentials are stored under
+func registryName(server string) string {
+	if docker.ServerKey(server) == docker.ServerKey("docker.io") {
+		return "docker.io"
+	}
+	return docker.ServerKey(server)
+}
+
+func (f *LoginForm) View() string {
+	lines := []string{theme.TitleStyle.Render("Registry login"), ""}
+
+	for i, field := range f.fields {
+		label := fmt.Sprintf("  %-10s", field.label)
+		if i == f.focus {
+			label = theme.SelectedStyle.Render(fmt.Sprintf("› %-10s", field.label))
+		}
+		lines = append(lines, label+" "+field.input.View())
+	}
+
+	lines = append(lines, "")
+	if f.err != "" {
+		lines = append(lines, theme.HighUsageStyle.Render(f.err), "")
+	}
+	lines = append(lines, theme.InactiveStyle.Render("Saved to ~/.docker/config.json or its credential helper"), "")
+
+	hints := []string{
+		theme.HelpKeyStyle.Render("Enter") + theme.HelpStyle.Render(":log in"),
+		theme.HelpKeyStyle.Render("Tab/↑↓") + theme.HelpStyle.Render(":field"),
+		theme.HelpKeyStyle.Render("Esc") + theme.HelpStyle.Render(":cancel"),
+	}
+	lines = append(lines, strings.Join(hints, "  "))
+
+	box := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Blue).
+		Padding(1, 3)
+
+	return box.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
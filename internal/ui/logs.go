@@ -4,21 +4,25 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/seb07-cloud/dktop/internal/docker"
 	"github.com/seb07-cloud/dktop/internal/theme"
 )
 
 type LogsPanel struct {
 	width         int
 	height        int
-	logs          []string
+	logs          *ring[docker.LogLine]
 	containerName string
 	active        bool
 	offset        int
 	autoScroll    bool
+	stream        docker.LogStream // 0 shows both streams
 }
 
-func NewLogsPanel() *LogsPanel {
+// NewLogsPanel creates a logs panel that keeps the last maxLines lines
+func NewLogsPanel(maxLines int) *LogsPanel {
 	return &LogsPanel{
+		logs:       newRing[docker.LogLine](maxLines),
 		autoScroll: true,
 	}
 }
@@ -35,40 +39,81 @@ func (p *LogsPanel) SetActive(active bool) {
 func (p *LogsPanel) SetContainerName(name string) {
 	if p.containerName != name {
 		p.containerName = name
-		p.logs = nil
-		p.offset = 0
-		p.autoScroll = true
+		p.Clear()
 	}
 }
 
-func (p *LogsPanel) Update(logs string) {
-	lines := strings.Split(logs, "\n")
-	p.logs = lines
+// Clear drops all buffered lines
+func (p *LogsPanel) Clear() {
+	p.logs.Reset()
+	p.offset = 0
+	p.autoScroll = true
+}
+
+func (p *LogsPanel) AppendLog(line docker.LogLine) {
+	// The ring buffer drops the oldest line once full; keep a locked
+	// scroll position on the same lines
+	if p.logs.Len() == len(p.logs.items) && !p.autoScroll && p.offset > 0 {
+		p.offset--
+	}
+	p.logs.Push(line)
 
 	// Auto-scroll to bottom if enabled
 	if p.autoScroll {
-		visibleLines := p.height - 4
-		if len(p.logs) > visibleLines {
-			p.offset = len(p.logs) - visibleLines
-		}
+		p.scrollToEnd()
 	}
 }
 
-func (p *LogsPanel) AppendLog(line string) {
-	p.logs = append(p.logs, line)
+// CycleStream switches between showing both streams, stdout only and
+// stderr only
+func (p *LogsPanel) CycleStream() {
+	switch p.stream {
+	case 0:
+		p.stream = docker.Stdout
+	case docker.Stdout:
+		p.stream = docker.Stderr
+	default:
+		p.stream = 0
+	}
+	p.autoScroll = true
+	p.scrollToEnd()
+}
 
-	// Keep only last 1000 lines
-	if len(p.logs) > 1000 {
-		p.logs = p.logs[len(p.logs)-1000:]
+// visible returns the lines that pass the stream filter
+func (p *LogsPanel) visible() []docker.LogLine {
+	if p.stream == 0 {
+		return p.logs.Slice()
 	}
+	var lines []docker.LogLine
+	for i := 0; i < p.logs.Len(); i++ {
+		if line := p.logs.At(i); line.Stream == p.stream {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
 
-	// Auto-scroll to bottom if enabled
-	if p.autoScroll {
-		visibleLines := p.height - 4
-		if len(p.logs) > visibleLines {
-			p.offset = len(p.logs) - visibleLines
+// visibleCount returns the number of lines that pass the stream filter
+func (p *LogsPanel) visibleCount() int {
+	if p.stream == 0 {
+		return p.logs.Len()
+	}
+	n := 0
+	for i := 0; i < p.logs.Len(); i++ {
+		if p.logs.At(i).Stream == p.stream {
+			n++
 		}
 	}
+	return n
+}
+
+func (p *LogsPanel) scrollToEnd() {
+	visibleLines := p.height - 4
+	total := p.visibleCount()
+	p.offset = 0
+	if total > visibleLines {
+		p.offset = total - visibleLines
+	}
 }
 
 func (p *LogsPanel) ScrollUp() {
@@ -80,7 +125,7 @@ func (p *LogsPanel) ScrollUp() {
 
 func (p *LogsPanel) ScrollDown() {
 	visibleLines := p.height - 4
-	maxOffset := len(p.logs) - visibleLines
+	maxOffset := p.visibleCount() - visibleLines
 	if maxOffset < 0 {
 		maxOffset = 0
 	}
@@ -94,10 +139,7 @@ func (p *LogsPanel) ScrollDown() {
 }
 
 func (p *LogsPanel) ScrollToBottom() {
-	visibleLines := p.height - 4
-	if len(p.logs) > visibleLines {
-		p.offset = len(p.logs) - visibleLines
-	}
+	p.scrollToEnd()
 	p.autoScroll = true
 }
 
@@ -111,8 +153,16 @@ func (p *LogsPanel) View() string {
 	if p.containerName != "" {
 		title += theme.InactiveStyle.Render(" [" + p.containerName + "]")
 	}
+	switch p.stream {
+	case docker.Stdout:
+		title += theme.InactiveStyle.Render(" [stdout only]")
+	case docker.Stderr:
+		title += theme.StderrStyle.Render(" [stderr only]")
+	}
 
-	if len(p.logs) == 0 || p.containerName == "" {
+	lines := p.visible()
+
+	if len(lines) == 0 || p.containerName == "" {
 		var msg string
 		if p.containerName == "" {
 			msg = "Select a container to view logs"
@@ -131,20 +181,27 @@ func (p *LogsPanel) View() string {
 	var rows []string
 	maxWidth := p.width - 6
 
-	for i := p.offset; i < len(p.logs) && i < p.offset+visibleLines; i++ {
-		line := p.logs[i]
+	for i := p.offset; i < len(lines) && i < p.offset+visibleLines; i++ {
+		line := lines[i].Text
 
 		// Truncate long lines
 		if len(line) > maxWidth {
 			line = line[:maxWidth-3] + "..."
 		}
 
+		textStyle := lipgloss.NewStyle()
+		if lines[i].Stream == docker.Stderr {
+			textStyle = theme.StderrStyle
+		}
+
 		// Color timestamps differently
 		if len(line) > 0 {
 			// Try to find timestamp (usually first part before space)
 			parts := strings.SplitN(line, " ", 2)
 			if len(parts) == 2 && (strings.Contains(parts[0], "T") || strings.Contains(parts[0], ":")) {
-				line = theme.InactiveStyle.Render(parts[0]) + " " + parts[1]
+				line = theme.InactiveStyle.Render(parts[0]) + " " + textStyle.Render(parts[1])
+			} else {
+				line = textStyle.Render(line)
 			}
 		}
 
@@ -0,0 +1,172 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rect is the screen area a panel was laid out in
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+// Offsets inside a bordered list panel: top border, title, column header
+// and a blank line come before the first row; the left border and padding
+// come before the first column.
+const (
+	panelHeaderLine = 2
+	panelFirstRow   = 4
+	panelFirstCol   = 2
+)
+
+// wheelLines is how far one wheel notch scrolls the logs
+const wheelLines = 3
+
+// panelAt returns the panel drawn at the given screen cell
+func (a *App) panelAt(x, y int) (Panel, rect, bool) {
+	candidates := []Panel{PanelStats, PanelImages}
+	if a.inContainerView() {
+		// These take over the containers and logs area
+		candidates = append(candidates, a.activePanel)
+	} else {
+		candidates = append(candidates, PanelContainers, PanelLogs)
+	}
+
+	for _, p := range candidates {
+		if box, ok := a.hitBoxes[p]; ok && box.contains(x, y) {
+			return p, box, true
+		}
+	}
+	return 0, rect{}, false
+}
+
+func (a *App) handleMouse(msg tea.MouseMsg) {
+	// Overlays and text inputs own the screen
+	if a.mode != ModeNormal {
+		return
+	}
+
+	panel, box, ok := a.panelAt(msg.X, msg.Y)
+	if !ok {
+		return
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		a.scrollPanel(panel, true)
+	case tea.MouseButtonWheelDown:
+		a.scrollPanel(panel, false)
+	case tea.MouseButtonLeft:
+		if msg.Action == tea.MouseActionPress {
+			a.clickPanel(panel, msg.X-box.x, msg.Y-box.y)
+		}
+	}
+}
+
+// scrollPanel scrolls the panel under the pointer without focusing it
+func (a *App) scrollPanel(panel Panel, up bool) {
+	switch panel {
+	case PanelContainers:
+		if up {
+			a.containersPanel.MoveUp()
+		} else {
+			a.containersPanel.MoveDown()
+		}
+	case PanelImages:
+		if up {
+			a.imagesPanel.MoveUp()
+		} else {
+			a.imagesPanel.MoveDown()
+		}
+	case PanelLogs:
+		for i := 0; i < wheelLines; i++ {
+			if up {
+				a.logsPanel.ScrollUp()
+			} else {
+				a.logsPanel.ScrollDown()
+			}
+		}
+	case PanelDetail:
+		for i := 0; i < wheelLines; i++ {
+			if up {
+				a.detailPanel.ScrollUp()
+			} else {
+				a.detailPanel.ScrollDown()
+			}
+		}
+	case PanelImageInfo:
+		for i := 0; i < wheelLines; i++ {
+			if up {
+				a.imageInfoPanel.ScrollUp()
+			} else {
+				a.imageInfoPanel.ScrollDown()
+			}
+		}
+	case PanelProcesses:
+		if up {
+			a.processesPanel.MoveUp()
+		} else {
+			a.processesPanel.MoveDown()
+		}
+	case PanelDiff:
+		if up {
+			a.diffPanel.MoveUp()
+		} else {
+			a.diffPanel.MoveDown()
+		}
+	case PanelFiles:
+		if up {
+			a.filesPanel.MoveUp()
+		} else {
+			a.filesPanel.MoveDown()
+		}
+	case PanelPull:
+		if up {
+			a.pullPanel.MoveUp()
+		} else {
+			a.pullPanel.MoveDown()
+		}
+	}
+}
+
+// clickPanel focuses the clicked panel and acts on the row or header under
+// the pointer. x and y are relative to the panel's top-left corner.
+func (a *App) clickPanel(panel Panel, x, y int) {
+	switch panel {
+	case PanelContainers:
+		a.activePanel = PanelContainers
+		if y == panelHeaderLine {
+			a.containersPanel.SortAt(x - panelFirstCol)
+		} else if y >= panelFirstRow {
+			a.containersPanel.SelectRow(y - panelFirstRow)
+		}
+	case PanelImages:
+		a.activePanel = PanelImages
+		if y >= panelFirstRow {
+			a.imagesPanel.SelectRow(y - panelFirstRow)
+		}
+	case PanelLogs:
+		a.activePanel = PanelLogs
+	case PanelProcesses:
+		if y >= panelFirstRow {
+			a.processesPanel.SelectRow(y - panelFirstRow)
+		}
+	case PanelDiff:
+		if y >= panelFirstRow {
+			a.diffPanel.SelectRow(y - panelFirstRow)
+		}
+	case PanelFiles:
+		if y >= panelFirstRow {
+			a.filesPanel.SelectRow(y - panelFirstRow)
+		}
+	case PanelPull:
+		// The queue starts right under the title
+		if y >= panelHeaderLine {
+			a.pullPanel.SelectRow(y - panelHeaderLine)
+		}
+	}
+	a.updatePanelActive()
+}
@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// ProcessesPanel lists the processes of a single container, busiest first
+type ProcessesPanel struct {
+	width       int
+	height      int
+	name        string
+	procs       []docker.ProcessInfo
+	loaded      bool
+	selected    int
+	selectedPID int
+	offset      int
+	stopped     error // why the list stopped updating, e.g. the container exited
+}
+
+func NewProcessesPanel() *ProcessesPanel {
+	return &ProcessesPanel{}
+}
+
+func (p *ProcessesPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.scrollToSelected()
+}
+
+// SetContainer clears the list for a newly shown container
+func (p *ProcessesPanel) SetContainer(name string) {
+	p.name = name
+	p.procs = nil
+	p.loaded = false
+	p.selected = 0
+	p.selectedPID = 0
+	p.offset = 0
+	p.stopped = nil
+}
+
+// SetProcesses replaces the list, keeping the cursor on the same process
+func (p *ProcessesPanel) SetProcesses(procs []docker.ProcessInfo) {
+	p.procs = procs
+	p.loaded = true
+	p.stopped = nil
+	sort.SliceStable(p.procs, func(i, j int) bool {
+		if p.procs[i].CPUPerc != p.procs[j].CPUPerc {
+			return p.procs[i].CPUPerc > p.procs[j].CPUPerc
+		}
+		return p.procs[i].PID < p.procs[j].PID
+	})
+
+	for i, proc := range p.procs {
+		if proc.PID == p.selectedPID {
+			p.selected = i
+			break
+		}
+	}
+	if p.selected >= len(p.procs) {
+		p.selected = max(len(p.procs)-1, 0)
+	}
+	p.rememberSelection()
+	p.scrollToSelected()
+}
+
+// Stop empties the list because the container has no processes to show
+// any more, e.g. it exited or was removed. The list stays empty until
+// SetProcesses is called again.
+func (p *ProcessesPanel) Stop(reason error) {
+	p.procs = nil
+	p.loaded = true
+	p.stopped = reason
+}
+
+// Stopped reports whether the list stopped updating
+func (p *ProcessesPanel) Stopped() bool {
+	return p.stopped != nil
+}
+
+func (p *ProcessesPanel) MoveUp() {
+	if p.selected > 0 {
+		p.selected--
+		p.rememberSelection()
+		p.scrollToSelected()
+	}
+}
+
+func (p *ProcessesPanel) MoveDown() {
+	if p.selected < len(p.procs)-1 {
+		p.selected++
+		p.rememberSelection()
+		p.scrollToSelected()
+	}
+}
+
+// SelectRow selects the process on a visible row, counted from the first
+// row below the header
+func (p *ProcessesPanel) SelectRow(row int) {
+	if i := p.offset + row; row >= 0 && i < len(p.procs) && row < p.visibleRows() {
+		p.selected = i
+		p.rememberSelection()
+	}
+}
+
+func (p *ProcessesPanel) GetSelected() *docker.ProcessInfo {
+	if p.selected >= 0 && p.selected < len(p.procs) {
+		return &p.procs[p.selected]
+	}
+	return nil
+}
+
+func (p *ProcessesPanel) rememberSelection() {
+	if proc := p.GetSelected(); proc != nil {
+		p.selectedPID = proc.PID
+	}
+}
+
+func (p *ProcessesPanel) visibleRows() int {
+	return max(p.height-5, 1)
+}
+
+func (p *ProcessesPanel) scrollToSelected() {
+	if p.selected < p.offset {
+		p.offset = p.selected
+	} else if p.selected >= p.offset+p.visibleRows() {
+		p.offset = p.selected - p.visibleRows() + 1
+	}
+}
+
+func (p *ProcessesPanel) View() string {
+	style := theme.ActivePanelStyle
+
+	title := theme.TitleStyle.Render(" Processes ")
+	if p.name != "" {
+		title += theme.InactiveStyle.Render(" [" + p.name + "]")
+	}
+	if p.loaded {
+		title += theme.InactiveStyle.Render(fmt.Sprintf(" %d processes", len(p.procs)))
+	}
+
+	if len(p.procs) == 0 {
+		msg := "Loading..."
+		if p.loaded {
+			msg = "No processes (container not running?)"
+		}
+		content := theme.InactiveStyle.Render(msg)
+		if p.stopped != nil {
+			content = theme.HighUsageStyle.Render(p.stopped.Error()) + "\n\n" +
+				theme.InactiveStyle.Render("No longer updating; r reloads")
+		}
+		return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n\n" + content)
+	}
+
+	const pidW, userW, cpuW, memW, rssW = 8, 12, 6, 6, 7
+	cmdW := max(p.width-6-pidW-userW-cpuW-memW-rssW-5, 10)
+
+	header := fmt.Sprintf("%*s %-*s %*s %*s %*s %s",
+		pidW, "PID", userW, "USER", cpuW, "CPU", memW, "MEM", rssW, "RSS", "COMMAND")
+
+	rows := []string{theme.HighlightStyle.Render(header), ""}
+	for i := p.offset; i < len(p.procs) && i < p.offset+p.visibleRows(); i++ {
+		proc := p.procs[i]
+		pid := fmt.Sprintf("%*d", pidW, proc.PID)
+		user := truncate(proc.User, userW)
+		cpu := fmt.Sprintf("%5.1f%%", proc.CPUPerc)
+		mem := fmt.Sprintf("%5.1f%%", proc.MemPerc)
+		rss := fmt.Sprintf("%*s", rssW, docker.FormatBytesShort(proc.RSS))
+		cmd := truncate(proc.Command, cmdW)
+
+		if i == p.selected {
+			row := fmt.Sprintf("%s %-*s %*s %*s %s %s", pid, userW, user, cpuW, cpu, memW, mem, rss, cmd)
+			rows = append(rows, theme.SelectedStyle.Width(p.width-4).Render(row))
+			continue
+		}
+		rows = append(rows, fmt.Sprintf("%s %-*s %s %s %s %s",
+			pid, userW, user,
+			theme.GetUsageStyle(proc.CPUPerc).Width(cpuW).Render(cpu),
+			theme.GetUsageStyle(proc.MemPerc).Width(memW).Render(mem),
+			rss, cmd))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n" + content)
+}
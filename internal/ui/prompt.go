@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// Prompt asks for a single line of text in the input bar and runs
+// onSubmit with it
+type Prompt struct {
+	label    string
+	input    textinput.Model
+	onSubmit func(value string) tea.Cmd
+}
+
+func NewPrompt(label, value string, onSubmit func(value string) tea.Cmd) *Prompt {
+	input := textinput.New()
+	input.CharLimit = 4096
+	input.SetValue(value)
+	input.Focus()
+	return &Prompt{label: label, input: input, onSubmit: onSubmit}
+}
+
+// HandleKey submits on Enter and cancels on Esc. It reports whether the
+// prompt is finished; other keys are left to Update.
+func (p *Prompt) HandleKey(key string) (bool, tea.Cmd) {
+	switch key {
+	case "enter":
+		return true, p.onSubmit(p.input.Value())
+	case "esc":
+		return true, nil
+	}
+	return false, nil
+}
+
+// Update passes a message on to the text input
+func (p *Prompt) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+func (p *Prompt) View() string {
+	return theme.HighlightStyle.Render(p.label+": ") + p.input.View()
+}
@@ -0,0 +1,469 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// rateWindow is how far back the throughput of a pull is averaged
+const rateWindow = 5 * time.Second
+
+type byteSample struct {
+	at    time.Time
+	bytes int64
+}
+
+// PullState is where a pull stands in the queue
+type PullState int
+
+const (
+	PullQueued PullState = iota
+	PullActive
+	PullDone
+	PullFailed
+	PullCancelled
+)
+
+func (s PullState) String() string {
+	switch s {
+	case PullQueued:
+		return "queued"
+	case PullActive:
+		return "pulling"
+	case PullDone:
+		return "done"
+	case PullFailed:
+		return "failed"
+	default:
+		return "cancelled"
+	}
+}
+
+func (s PullState) render(label string) string {
+	switch s {
+	case PullActive:
+		return theme.PausedStyle.Render(label)
+	case PullDone:
+		return theme.RunningStyle.Render(label)
+	case PullFailed:
+		return theme.StoppedStyle.Render(label)
+	default:
+		return theme.InactiveStyle.Render(label)
+	}
+}
+
+// PullJob is one image in the pull queue. Pushes share the queue; Push
+// marks them.
+type PullJob struct {
+	ID    int
+	Ref   string
+	Push  bool
+	State PullState
+	Err   error
+
+	progress docker.Progress
+	samples  []byteSample
+	cancel   context.CancelFunc
+}
+
+// Verb is what the job does to its image: "pull" or "push"
+func (j *PullJob) Verb() string {
+	if j.Push {
+		return "push"
+	}
+	return "pull"
+}
+
+// status is the job's state as shown, telling pushes from pulls
+func (j *PullJob) status() string {
+	if j.Push && j.State == PullActive {
+		return "pushing"
+	}
+	return j.State.String()
+}
+
+// label names the job in the queue; pushes are marked with an arrow
+func (j *PullJob) label() string {
+	if j.Push {
+		return "↑ " + j.Ref
+	}
+	return j.Ref
+}
+
+// start marks the job as pulling from now on; cancel stops the pull
+func (j *PullJob) start(now time.Time, cancel context.CancelFunc) {
+	j.State = PullActive
+	j.Err = nil
+	j.progress = docker.Progress{}
+	j.samples = []byteSample{{at: now}}
+	j.cancel = cancel
+}
+
+// Apply updates the layers with progress messages received at now
+func (j *PullJob) Apply(msgs []docker.ProgressMessage, now time.Time) {
+	for _, m := range msgs {
+		j.progress.Apply(m)
+	}
+	done, _ := j.progress.Bytes()
+	j.samples = append(j.samples, byteSample{at: now, bytes: done})
+	for len(j.samples) > 2 && now.Sub(j.samples[0].at) > rateWindow {
+		j.samples = j.samples[1:]
+	}
+}
+
+// Finish ends the pull; err is nil if it succeeded
+func (j *PullJob) Finish(err error) {
+	j.cancel = nil
+	j.Err = err
+	switch {
+	case errors.Is(err, context.Canceled):
+		j.State = PullCancelled
+	case err != nil:
+		j.State = PullFailed
+	default:
+		j.State = PullDone
+	}
+}
+
+// Cancel stops the pull, or drops it from the queue if it hasn't started
+func (j *PullJob) Cancel() {
+	switch j.State {
+	case PullActive:
+		if j.cancel != nil {
+			j.cancel()
+		}
+	case PullQueued:
+		j.Finish(context.Canceled)
+	}
+}
+
+// rate is the recent throughput in bytes per second
+func (j *PullJob) rate() float64 {
+	if len(j.samples) < 2 {
+		return 0
+	}
+	first, last := j.samples[0], j.samples[len(j.samples)-1]
+	elapsed := last.at.Sub(first.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(last.bytes-first.bytes) / elapsed
+}
+
+// eta estimates the time left for the layers sized so far
+func (j *PullJob) eta() (time.Duration, bool) {
+	done, total := j.progress.Bytes()
+	rate := j.rate()
+	if rate <= 0 || total <= done {
+		return 0, false
+	}
+	return time.Duration(float64(total-done) / rate * float64(time.Second)).Round(time.Second), true
+}
+
+func (j *PullJob) summary(barW int) string {
+	done, total := j.progress.Bytes()
+	line := docker.FormatBytes(uint64(done))
+	if total > 0 {
+		percent := float64(done) / float64(total) * 100
+		line = theme.RenderProgressBar(percent, barW) +
+			fmt.Sprintf(" %3.0f%%  %s / %s", percent, line, docker.FormatBytes(uint64(total)))
+	}
+	if j.State == PullActive {
+		line += "  " + formatRate(j.rate())
+		if eta, ok := j.eta(); ok {
+			line += "  ETA " + eta.String()
+		}
+	}
+	return line
+}
+
+// ParsePullRefs splits the pull prompt into image references. References
+// are separated by whitespace or commas, so a pasted list works too.
+func ParsePullRefs(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
+
+// PullPanel shows the pull queue and the layers of the selected pull
+type PullPanel struct {
+	width    int
+	height   int
+	selected int
+	offset   int
+
+	jobs   []*PullJob
+	nextID int
+}
+
+func NewPullPanel() *PullPanel {
+	return &PullPanel{}
+}
+
+func (p *PullPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// Add queues a pull of each ref and selects the first. A ref already
+// queued or pulling isn't added twice.
+func (p *PullPanel) Add(refs []string) []*PullJob {
+	return p.add(refs, false)
+}
+
+// AddPush queues a push of a tag and selects it. It returns nil if the
+// tag is already being pushed.
+func (p *PullPanel) AddPush(ref string) *PullJob {
+	if added := p.add([]string{ref}, true); len(added) > 0 {
+		return added[0]
+	}
+	return nil
+}
+
+func (p *PullPanel) add(refs []string, push bool) []*PullJob {
+	var added []*PullJob
+	for _, ref := range refs {
+		if p.pending(ref, push) {
+			continue
+		}
+		p.nextID++
+		job := &PullJob{ID: p.nextID, Ref: ref, Push: push}
+		p.jobs = append(p.jobs, job)
+		added = append(added, job)
+	}
+	if len(added) > 0 {
+		p.selectJob(len(p.jobs) - len(added))
+	}
+	return added
+}
+
+func (p *PullPanel) pending(ref string, push bool) bool {
+	for _, j := range p.jobs {
+		if j.Ref == ref && j.Push == push && (j.State == PullQueued || j.State == PullActive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Jobs lists the queue in the order the pulls were added
+func (p *PullPanel) Jobs() []*PullJob {
+	return p.jobs
+}
+
+// Job returns the pull with the given ID, or nil
+func (p *PullPanel) Job(id int) *PullJob {
+	for _, j := range p.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+// Next returns the longest queued pull, or nil
+func (p *PullPanel) Next() *PullJob {
+	for _, j := range p.jobs {
+		if j.State == PullQueued {
+			return j
+		}
+	}
+	return nil
+}
+
+// Count returns how many pulls are in the given state
+func (p *PullPanel) Count(state PullState) int {
+	n := 0
+	for _, j := range p.jobs {
+		if j.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Active reports whether any pull is queued or running
+func (p *PullPanel) Active() bool {
+	return p.Count(PullQueued)+p.Count(PullActive) > 0
+}
+
+func (p *PullPanel) GetSelected() *PullJob {
+	if p.selected >= 0 && p.selected < len(p.jobs) {
+		return p.jobs[p.selected]
+	}
+	return nil
+}
+
+// Retry queues a failed or cancelled pull again
+func (p *PullPanel) Retry(j *PullJob) bool {
+	if j == nil || (j.State != PullFailed && j.State != PullCancelled) || p.pending(j.Ref, j.Push) {
+		return false
+	}
+	j.State = PullQueued
+	j.Err = nil
+	j.progress = docker.Progress{}
+	j.samples = nil
+	return true
+}
+
+// ClearFinished drops the pulls that are done
+func (p *PullPanel) ClearFinished() {
+	selected := p.GetSelected()
+	kept := p.jobs[:0]
+	for _, j := range p.jobs {
+		if j.State != PullDone {
+			kept = append(kept, j)
+		}
+	}
+	p.jobs = kept
+	p.selected = 0
+	for i, j := range p.jobs {
+		if j == selected {
+			p.selected = i
+		}
+	}
+	p.selectJob(p.selected)
+}
+
+func (p *PullPanel) MoveUp() {
+	p.selectJob(p.selected - 1)
+}
+
+func (p *PullPanel) MoveDown() {
+	p.selectJob(p.selected + 1)
+}
+
+// SelectRow selects the pull on the given visible row
+func (p *PullPanel) SelectRow(row int) {
+	if i := p.offset + row; row < p.listRows() && i < len(p.jobs) {
+		p.selectJob(i)
+	}
+}
+
+func (p *PullPanel) selectJob(i int) {
+	p.selected = max(min(i, len(p.jobs)-1), 0)
+	rows := p.listRows()
+	if p.selected < p.offset {
+		p.offset = p.selected
+	} else if p.selected >= p.offset+rows {
+		p.offset = p.selected - rows + 1
+	}
+}
+
+// listRows is the height of the queue list; the selected pull's layers
+// get the rest of the panel
+func (p *PullPanel) listRows() int {
+	return max(min(len(p.jobs), (p.height-6)/3), 1)
+}
+
+func (p *PullPanel) title() string {
+	title := theme.TitleStyle.Render(" Pulls ")
+	var counts []string
+	for _, s := range []PullState{PullActive, PullQueued, PullDone, PullFailed} {
+		n := p.Count(s)
+		if s == PullActive {
+			if pushes := p.pushing(); pushes > 0 {
+				counts = append(counts, fmt.Sprintf("%d pushing", pushes))
+				n -= pushes
+			}
+		}
+		if n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, s))
+		}
+	}
+	if len(counts) > 0 {
+		title += " " + theme.InactiveStyle.Render(strings.Join(counts, ", "))
+	}
+	return title
+}
+
+// pushing counts the running pushes
+func (p *PullPanel) pushing() int {
+	n := 0
+	for _, j := range p.jobs {
+		if j.Push && j.State == PullActive {
+			n++
+		}
+	}
+	return n
+}
+
+func (p *PullPanel) View() string {
+	style := theme.ActivePanelStyle
+	title := p.title()
+
+	if len(p.jobs) == 0 {
+		content := theme.InactiveStyle.Render("No pulls yet; press p in the images panel, or u to push")
+		return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n\n" + content)
+	}
+
+	const stateW = 10
+	refW := max(min(p.width/3, 50), 12)
+	var rows []string
+	end := min(p.offset+p.listRows(), len(p.jobs))
+	for i := p.offset; i < end; i++ {
+		j := p.jobs[i]
+		detail := ""
+		switch j.State {
+		case PullActive, PullDone:
+			detail = j.summary(20)
+		case PullFailed:
+			detail = theme.HighUsageStyle.Render(truncate(j.Err.Error(), max(p.width-refW-stateW-8, 10)))
+		}
+		row := fmt.Sprintf("%-*s %s %s", refW, truncate(j.label(), refW),
+			j.State.render(j.status())+strings.Repeat(" ", max(stateW-len(j.status()), 0)), detail)
+		if i == p.selected {
+			row = theme.SelectedStyle.Render("›") + " " + row
+		} else {
+			row = "  " + row
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, "")
+
+	if j := p.GetSelected(); j != nil {
+		rows = append(rows, p.layers(j, p.height-3-len(rows))...)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	return style.Width(p.width - 2).Height(p.height - 2).Render(title + "\n" + content)
+}
+
+// layers renders the selected pull: its status line and up to n layers
+func (p *PullPanel) layers(j *PullJob, n int) []string {
+	header := theme.HighlightStyle.Render(j.label()) + " " + j.State.render(j.status())
+	status := theme.InactiveStyle.Render(j.progress.Status)
+	if j.Err != nil && !errors.Is(j.Err, context.Canceled) {
+		status = theme.HighUsageStyle.Render("Error: " + j.Err.Error())
+	}
+	rows := []string{header, j.summary(30), status}
+
+	const idW, statusW, barW = 12, 20, 20
+	for _, l := range j.progress.Layers {
+		if len(rows) >= n {
+			break
+		}
+
+		bar := ""
+		amount := ""
+		switch {
+		case l.Done:
+			bar = theme.RenderProgressBar(100, barW)
+		case l.Total > 0:
+			bar = theme.RenderProgressBar(float64(l.Current)/float64(l.Total)*100, barW)
+			amount = docker.FormatBytes(uint64(l.Current)) + " / " + docker.FormatBytes(uint64(l.Total))
+		default:
+			bar = theme.RenderProgressBar(0, barW)
+		}
+		rows = append(rows, fmt.Sprintf("%-*s %-*s %s %s",
+			idW, truncate(l.ID, idW), statusW, truncate(l.Status, statusW), bar, amount))
+	}
+	return rows
+}
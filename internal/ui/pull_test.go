@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seb07-cloud/dktop/internal/docker"
+)
+
+func TestPullJobRateAndETA(t *testing.T) {
+	p := &PullJob{Ref: "big:1"}
+	start := time.Unix(1000, 0)
+	p.start(start, func() {})
+
+	download := func(current int64, at time.Duration) {
+		p.Apply([]docker.ProgressMessage{{ID: "l1", Status: "Downloading", Current: current, Total: 100 << 20}}, start.Add(at))
+	}
+	download(10<<20, 1*time.Second)
+	download(20<<20, 2*time.Second)
+	if rate := p.rate(); rate != 10<<20 {
+		t.Errorf("rate = %v, want 10MB/s", rate)
+	}
+	if eta, ok := p.eta(); !ok || eta != 8*time.Second {
+		t.Errorf("eta = %v, %v; want 8s", eta, ok)
+	}
+
+	// Only the recent window counts: a stall long ago doesn't drag the
+	// rate down
+	download(20<<20, 10*time.Second)
+	download(60<<20, 12*time.Second)
+	if rate := p.rate(); rate < 15<<20 {
+		t.Errorf("rate = %v, want the recent rate", rate)
+	}
+}
+
+func TestParsePullRefs(t *testing.T) {
+	got := ParsePullRefs(" nginx:1.27, redis:7\n\npostgres:16\r\n")
+	want := []string{"nginx:1.27", "redis:7", "postgres:16"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ParsePullRefs = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package ui
+
+// ring is a fixed-capacity buffer that overwrites its oldest entries once full
+type ring[T any] struct {
+	items []T
+	start int
+	size  int
+}
+
+func newRing[T any](capacity int) *ring[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &ring[T]{items: make([]T, capacity)}
+}
+
+// Push appends v, dropping the oldest entry if the buffer is full
+func (r *ring[T]) Push(v T) {
+	if r.size < len(r.items) {
+		r.items[(r.start+r.size)%len(r.items)] = v
+		r.size++
+		return
+	}
+	r.items[r.start] = v
+	r.start = (r.start + 1) % len(r.items)
+}
+
+// Len returns the number of entries held
+func (r *ring[T]) Len() int {
+	return r.size
+}
+
+// At returns the i-th oldest entry
+func (r *ring[T]) At(i int) T {
+	return r.items[(r.start+i)%len(r.items)]
+}
+
+// Last returns the newest entry and whether there is one
+func (r *ring[T]) Last() (T, bool) {
+	if r.size == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.At(r.size - 1), true
+}
+
+// Slice returns the entries from oldest to newest
+func (r *ring[T]) Slice() []T {
+	out := make([]T, r.size)
+	for i := range out {
+		out[i] = r.At(i)
+	}
+	return out
+}
+
+// Reset empties the buffer
+func (r *ring[T]) Reset() {
+	var zero T
+	for i := range r.items {
+		r.items[i] = zero
+	}
+	r.start = 0
+	r.size = 0
+}
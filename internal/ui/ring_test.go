@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRingOverwritesOldest(t *testing.T) {
+	r := newRing[int](3)
+	for i := 1; i <= 5; i++ {
+		r.Push(i)
+	}
+
+	if got := r.Slice(); !reflect.DeepEqual(got, []int{3, 4, 5}) {
+		t.Fatalf("Slice() = %v, want [3 4 5]", got)
+	}
+	if last, ok := r.Last(); !ok || last != 5 {
+		t.Fatalf("Last() = %d, %v", last, ok)
+	}
+
+	r.Reset()
+	if r.Len() != 0 {
+		t.Fatalf("Len() after Reset = %d", r.Len())
+	}
+	if _, ok := r.Last(); ok {
+		t.Fatal("Last() on empty ring should report false")
+	}
+}
@@ -0,0 +1,347 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/seb07-cloud/dktop/internal/config"
+	"github.com/seb07-cloud/dktop/internal/docker"
+	"github.com/seb07-cloud/dktop/internal/theme"
+)
+
+// restartPolicies are offered by the run form
+var restartPolicies = []string{"no", "always", "unless-stopped", "on-failure"}
+
+// runField is one row of the run form: a text input, a choice cycled with
+// the arrow keys, or an on/off toggle
+type runField struct {
+	label   string
+	input   *textinput.Model
+	choices []string
+	choice  int
+	toggle  bool
+	on      bool
+}
+
+func newRunTextField(label, placeholder string) *runField {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.CharLimit = 1024
+	input.Width = 50
+	return &runField{label: label, input: &input}
+}
+
+func (f *runField) value() string {
+	if f.input != nil {
+		return strings.TrimSpace(f.input.Value())
+	}
+	if len(f.choices) > 0 {
+		return f.choices[f.choice]
+	}
+	return ""
+}
+
+// setChoice selects value, adding it if it isn't one of the choices
+func (f *runField) setChoice(value string) {
+	for i, c := range f.choices {
+		if c == value {
+			f.choice = i
+			return
+		}
+	}
+	f.choices = append(f.choices, value)
+	f.choice = len(f.choices) - 1
+}
+
+// RunForm collects docker run options for an image. It can be filled from
+// a preset saved for the image and saved as one.
+type RunForm struct {
+	image   string
+	presets []config.RunPreset
+
+	preset, name, command, env, ports, volumes, network, restart, autoRemove, saveAs *runField
+
+	fields []*runField
+	focus  int
+	err    string
+	status string
+
+	onRun  func(opts docker.RunOptions) tea.Cmd
+	onSave func(preset config.RunPreset) error
+}
+
+func NewRunForm(image string, presets []config.RunPreset, onRun func(docker.RunOptions) tea.Cmd, onSave func(config.RunPreset) error) *RunForm {
+	f := &RunForm{
+		image:      image,
+		presets:    presets,
+		name:       newRunTextField("Name", "generated"),
+		command:    newRunTextField("Command", "image default"),
+		env:        newRunTextField("Env", "KEY=value ..."),
+		ports:      newRunTextField("Ports", "8080:80 127.0.0.1:5432:5432 ..."),
+		volumes:    newRunTextField("Volumes", "/host/path:/path[:ro] volume:/path ..."),
+		network:    newRunTextField("Network", "default"),
+		restart:    &runField{label: "Restart", choices: append([]string(nil), restartPolicies...)},
+		autoRemove: &runField{label: "Auto-remove", toggle: true},
+		saveAs:     newRunTextField("Save as", "preset name (Ctrl+S saves)"),
+		onRun:      onRun,
+		onSave:     onSave,
+	}
+	if len(presets) > 0 {
+		choices := []string{"(none)"}
+		for _, p := range presets {
+			choices = append(choices, p.Name)
+		}
+		f.preset = &runField{label: "Preset", choices: choices}
+		f.fields = append(f.fields, f.preset)
+	}
+	f.fields = append(f.fields, f.name, f.command, f.env, f.ports, f.volumes, f.network, f.restart, f.autoRemove, f.saveAs)
+	f.setFocus(0)
+	return f
+}
+
+func (f *RunForm) setFocus(i int) {
+	f.focus = (i + len(f.fields)) % len(f.fields)
+	for j, field := range f.fields {
+		if field.input == nil {
+			continue
+		}
+		if j == f.focus {
+			field.input.Focus()
+		} else {
+			field.input.Blur()
+		}
+	}
+}
+
+// applyPreset fills the form from a saved preset
+func (f *RunForm) applyPreset(p config.RunPreset) {
+	f.name.input.SetValue(p.ContainerName)
+	f.command.input.SetValue(p.Command)
+	f.env.input.SetValue(joinFields(p.Env))
+	f.ports.input.SetValue(joinFields(p.Ports))
+	f.volumes.input.SetValue(joinFields(p.Volumes))
+	f.network.input.SetValue(p.Network)
+	f.restart.setChoice(p.Restart)
+	if p.Restart == "" {
+		f.restart.choice = 0
+	}
+	f.autoRemove.on = p.AutoRemove
+	f.saveAs.input.SetValue(p.Name)
+}
+
+// HandleKey processes the keys that drive the form; typing is left to
+// Update. It returns done once the form should close, along with the run
+// command if it was submitted.
+func (f *RunForm) HandleKey(key string) (done bool, cmd tea.Cmd) {
+	field := f.fields[f.focus]
+	switch key {
+	case "esc":
+		return true, nil
+	case "enter":
+		opts, err := f.options()
+		if err != nil {
+			f.err = err.Error()
+			return false, nil
+		}
+		return true, f.onRun(opts)
+	case "ctrl+s":
+		f.save()
+	case "tab", "down":
+		f.setFocus(f.focus + 1)
+	case "shift+tab", "up":
+		f.setFocus(f.focus - 1)
+	case "right", "left", " ":
+		switch {
+		case field.toggle:
+			field.on = !field.on
+		case len(field.choices) > 0:
+			step := 1
+			if key == "left" {
+				step = len(field.choices) - 1
+			}
+			field.choice = (field.choice + step) % len(field.choices)
+			if field == f.preset && field.choice > 0 {
+				f.applyPreset(f.presets[field.choice-1])
+			}
+		}
+	}
+	return false, nil
+}
+
+// Update passes a message on to the focused text input
+func (f *RunForm) Update(msg tea.Msg) tea.Cmd {
+	field := f.fields[f.focus]
+	if field.input == nil {
+		return nil
+	}
+	var cmd tea.Cmd
+	*field.input, cmd = field.input.Update(msg)
+	return cmd
+}
+
+func (f *RunForm) save() {
+	f.err, f.status = "", ""
+	name := f.saveAs.value()
+	if name == "" {
+		f.err = "enter a preset name under Save as"
+		return
+	}
+	if _, err := f.options(); err != nil {
+		f.err = err.Error()
+		return
+	}
+	p := config.RunPreset{
+		Name:          name,
+		Image:         f.image,
+		ContainerName: f.name.value(),
+		Command:       f.command.value(),
+		Network:       f.network.value(),
+		AutoRemove:    f.autoRemove.on,
+	}
+	p.Env, _ = splitFields(f.env.value())
+	p.Ports, _ = splitFields(f.ports.value())
+	p.Volumes, _ = splitFields(f.volumes.value())
+	if restart := f.restart.value(); restart != "no" {
+		p.Restart = restart
+	}
+	if err := f.onSave(p); err != nil {
+		f.err = err.Error()
+		return
+	}
+	f.status = "saved preset " + name
+}
+
+// options reads the form into run options
+func (f *RunForm) options() (docker.RunOptions, error) {
+	opts := docker.RunOptions{
+		Image:      f.image,
+		Name:       f.name.value(),
+		Network:    f.network.value(),
+		Restart:    f.restart.value(),
+		AutoRemove: f.autoRemove.on,
+	}
+	for _, list := range []struct {
+		field *runField
+		dst   *[]string
+	}{
+		{f.command, &opts.Cmd},
+		{f.env, &opts.Env},
+		{f.ports, &opts.Ports},
+		{f.volumes, &opts.Volumes},
+	} {
+		values, err := splitFields(list.field.value())
+		if err != nil {
+			return opts, fmt.Errorf("%s: %w", list.field.label, err)
+		}
+		*list.dst = values
+	}
+	return opts, nil
+}
+
+// splitFields splits s at whitespace like a shell would: quotes keep a
+// field together and a backslash escapes the next character
+func splitFields(s string) ([]string, error) {
+	var fields []string
+	var cur strings.Builder
+	inField, quote, escaped := false, rune(0), false
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inField = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inField = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inField {
+				fields = append(fields, cur.String())
+				cur.Reset()
+				inField = false
+			}
+		default:
+			cur.WriteRune(r)
+			inField = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inField {
+		fields = append(fields, cur.String())
+	}
+	return fields, nil
+}
+
+// joinFields is the inverse of splitFields, quoting fields that need it
+func joinFields(fields []string) string {
+	quoted := make([]string, len(fields))
+	for i, f := range fields {
+		if f == "" || strings.ContainsAny(f, " \t\n\"'\\") {
+			f = "'" + strings.ReplaceAll(f, "'", `'\''`) + "'"
+		}
+		quoted[i] = f
+	}
+	return strings.Join(quoted, " ")
+}
+
+func (f *RunForm) View() string {
+	lines := []string{
+		theme.TitleStyle.Render("Run container") + "  " + theme.HighlightStyle.Render(f.image),
+		"",
+	}
+
+	for i, field := range f.fields {
+		label := fmt.Sprintf("  %-12s", field.label)
+		if i == f.focus {
+			label = theme.SelectedStyle.Render(fmt.Sprintf("› %-12s", field.label))
+		}
+
+		var value string
+		switch {
+		case field.input != nil:
+			value = field.input.View()
+		case field.toggle:
+			value = "[ ]"
+			if field.on {
+				value = "[x]"
+			}
+		default:
+			value = "‹ " + field.value() + " ›"
+		}
+		lines = append(lines, label+" "+value)
+	}
+
+	lines = append(lines, "")
+	if f.err != "" {
+		lines = append(lines, theme.HighUsageStyle.Render(f.err), "")
+	} else if f.status != "" {
+		lines = append(lines, theme.RunningStyle.Render(f.status), "")
+	}
+
+	hints := []string{
+		theme.HelpKeyStyle.Render("Enter") + theme.HelpStyle.Render(":run"),
+		theme.HelpKeyStyle.Render("Tab/↑↓") + theme.HelpStyle.Render(":field"),
+		theme.HelpKeyStyle.Render("←/→") + theme.HelpStyle.Render(":change"),
+		theme.HelpKeyStyle.Render("Ctrl+S") + theme.HelpStyle.Render(":save preset"),
+		theme.HelpKeyStyle.Render("Esc") + theme.HelpStyle.Render(":cancel"),
+	}
+	lines = append(lines, strings.Join(hints, "  "))
+
+	box := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Blue).
+		Padding(1, 3)
+
+	return box.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitFields(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  a  b\tc ", []string{"a", "b", "c"}},
+		{`sh -c "echo hello world"`, []string{"sh", "-c", "echo hello world"}},
+		{`'GREETING=it''s' A=\"x\"`, []string{"GREETING=its", `A="x"`}},
+		{`path\ with\ spaces:/data ''`, []string{"path with spaces:/data", ""}},
+	}
+	for _, tt := range tests {
+		got, err := splitFields(tt.in)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitFields(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+		if back, _ := splitFields(joinFields(got)); !reflect.DeepEqual(back, got) {
+			t.Errorf("joinFields(%q) does not split back: %q", got, back)
+		}
+	}
+
+	if _, err := splitFields(`echo "unterminated`); err == nil {
+		t.Error("unterminated quote accepted")
+	}
+}
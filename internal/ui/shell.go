@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/cancelreader"
+	"github.com/seb07-cloud/dktop/internal/docker"
+)
+
+// resizeInterval is how often the terminal size is checked while a shell
+// runs. Polling works the same on every platform, unlike SIGWINCH.
+const resizeInterval = 250 * time.Millisecond
+
+// shellExitedMsg is sent once the TUI is back after an exec session
+type shellExitedMsg struct {
+	name string
+	err  error
+}
+
+// shellCommand runs an interactive exec session on the terminal the
+// Bubble Tea program hands over while it is suspended. It implements
+// tea.ExecCommand, the interface behind tea.ExecProcess, for a process that
+// runs in a container rather than locally.
+type shellCommand struct {
+	runtime     docker.Runtime
+	containerID string
+	opts        docker.ExecOptions
+
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+func (s *shellCommand) SetStdin(r io.Reader)  { s.stdin = r }
+func (s *shellCommand) SetStdout(w io.Writer) { s.stdout = w }
+func (s *shellCommand) SetStderr(io.Writer)   {} // a TTY merges stderr into stdout
+
+func (s *shellCommand) Run() error {
+	if s.stdin == nil {
+		s.stdin = os.Stdin
+	}
+	if s.stdout == nil {
+		s.stdout = os.Stdout
+	}
+
+	// Keys go to the shell unprocessed, as in docker exec -it
+	inFd, inTerm := terminalFd(s.stdin)
+	if inTerm {
+		state, err := term.MakeRaw(inFd)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = term.Restore(inFd, state) }()
+	}
+
+	outFd, outTerm := terminalFd(s.stdout)
+	opts := s.opts
+	if outTerm {
+		if w, h, err := term.GetSize(outFd); err == nil {
+			opts.Width, opts.Height = uint(w), uint(h)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	session, err := s.runtime.ExecShell(ctx, s.containerID, opts)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	// The input copy must stop reading once the shell exits, or it would
+	// swallow the first key pressed back in the TUI
+	in, err := cancelreader.NewReader(s.stdin)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	go func() {
+		_, _ = io.Copy(session, in)
+	}()
+
+	if outTerm {
+		go watchSize(ctx, outFd, opts.Width, opts.Height, session)
+	}
+
+	_, err = io.Copy(s.stdout, session)
+	in.Cancel()
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return err
+}
+
+// watchSize resizes the session's TTY whenever the terminal changes size
+func watchSize(ctx context.Context, fd uintptr, width, height uint, session docker.ExecSession) {
+	ticker := time.NewTicker(resizeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w, h, err := term.GetSize(fd)
+			if err != nil || (uint(w) == width && uint(h) == height) {
+				continue
+			}
+			width, height = uint(w), uint(h)
+			_ = session.Resize(ctx, width, height)
+		}
+	}
+}
+
+// terminalFd returns the file descriptor behind f if it is a terminal
+func terminalFd(f any) (uintptr, bool) {
+	file, ok := f.(interface{ Fd() uintptr })
+	if !ok || !term.IsTerminal(file.Fd()) {
+		return 0, false
+	}
+	return file.Fd(), true
+}
+
+// openShell suspends the TUI and opens a shell in the selected container
+func (a *App) openShell() tea.Cmd {
+	c := a.containersPanel.GetSelected()
+	if c == nil {
+		return nil
+	}
+	if c.State != "running" {
+		a.err = errors.New("exec: " + c.Name + " is not running")
+		return nil
+	}
+
+	cmd := &shellCommand{
+		runtime:     a.dockerClient,
+		containerID: c.ID,
+		opts: docker.ExecOptions{
+			Shells:  a.config.Exec.Shells,
+			User:    a.config.Exec.User,
+			WorkDir: a.config.Exec.WorkDir,
+		},
+	}
+	name := c.Name
+	return tea.Exec(cmd, func(err error) tea.Msg {
+		return shellExitedMsg{name: name, err: err}
+	})
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/seb07-cloud/dktop/internal/docker"
+)
+
+func TestShellCommandCopiesSessionOutput(t *testing.T) {
+	fake := seeded()
+	fake.SetExecOutput("web1", "root@web1:/# exit\r\n")
+
+	var out bytes.Buffer
+	cmd := &shellCommand{
+		runtime:     fake,
+		containerID: "web1",
+		opts:        docker.ExecOptions{Shells: []string{"/bin/bash", "/bin/sh"}, User: "app"},
+	}
+	cmd.SetStdin(strings.NewReader("exit\r"))
+	cmd.SetStdout(&out)
+
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if got := out.String(); got != "root@web1:/# exit\r\n" {
+		t.Errorf("output = %q", got)
+	}
+
+	calls := fake.Calls("ExecShell")
+	if len(calls) != 1 {
+		t.Fatalf("ExecShell called %d times, want 1", len(calls))
+	}
+	if opts := calls[0].Args[1].(docker.ExecOptions); opts.User != "app" || len(opts.Shells) != 2 {
+		t.Errorf("ExecShell options = %+v", opts)
+	}
+}
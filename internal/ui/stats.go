@@ -15,6 +15,7 @@ type StatsPanel struct {
 	width        int
 	height       int
 	stats        *docker.SystemStats
+	host         string
 	active       bool
 	cpuSparkline sparkline.Model
 	memSparkline sparkline.Model
@@ -55,6 +56,25 @@ func (p *StatsPanel) SetActive(active bool) {
 	p.active = active
 }
 
+// SetHost sets the name of the active Docker host. Switching hosts clears
+// the graphs, since their history belongs to the previous engine.
+func (p *StatsPanel) SetHost(name string) {
+	if p.host == name {
+		return
+	}
+	p.host = name
+	p.stats = nil
+	if p.initialized {
+		p.initialized = false
+		p.SetSize(p.width, p.height)
+	}
+}
+
+// SetStats replaces the displayed stats without adding a graph sample
+func (p *StatsPanel) SetStats(stats *docker.SystemStats) {
+	p.stats = stats
+}
+
 func (p *StatsPanel) Update(stats *docker.SystemStats) {
 	p.stats = stats
 
@@ -78,6 +98,9 @@ func (p *StatsPanel) View() string {
 	}
 
 	title := theme.TitleStyle.Render(" Docker Stats ")
+	if p.host != "" {
+		title += theme.InactiveStyle.Render(" [" + p.host + "]")
+	}
 
 	if p.stats == nil {
 		content := theme.InactiveStyle.Render("Loading...")